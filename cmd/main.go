@@ -3,11 +3,11 @@ package main
 import (
 	"easy-check/internal/checker"
 	"easy-check/internal/config"
-	"easy-check/internal/db"
 	"easy-check/internal/initializer"
 	"easy-check/internal/logger"
 	"easy-check/internal/machineid"
 	"easy-check/internal/notifier"
+	"easy-check/internal/report"
 	"easy-check/internal/scheduler"
 	"easy-check/internal/signal"
 	"fmt"
@@ -38,20 +38,37 @@ func main() {
 	}
 	defer appCtx.Logger.Close()
 
-	// 创建一个通道用于控制定时器
-	tickerControlChan := make(chan time.Duration)
+	// 然后初始化 pinger 和 checker
+	pinger := checker.NewPinger()
+	// 检测器、通知消费者、报告和界面共用同一个 AlertStatusManager，记录的读-改-写才会互斥
+	alertStatusManager := appCtx.AlertStatusMgr
+	appCtx.Logger.Log("Application started successfully", "info")
 
-	// 首先启动配置文件监听
-	go config.WatchConfigFile(filepath.Join("configs", "config.yaml"), appCtx.Logger, func(newConfig *config.Config) {
-		// 更新配置
-		oldInterval := appCtx.Config.Interval
-		oldPingInterval := appCtx.Config.Ping.Interval
+	interval := time.Duration(appCtx.Config.Alert.AggregateWindow) * time.Second
+	consumer := notifier.NewConsumer(alertStatusManager, appCtx.SilenceMgr, appCtx.Logger, interval, appCtx.AggregatorHandle)
+	consumer.UpdateConfig(appCtx.Config)
+	go consumer.Start()
 
+	// 定期发送可用性报告
+	reporter := report.NewReporter(appCtx.Config, alertStatusManager, appCtx.TSDB, appCtx.Notifier, appCtx.Logger)
+	go reporter.Start()
+
+	chk := checker.NewChecker(appCtx.Config, pinger, appCtx.Logger, alertStatusManager, appCtx.TSDB)
+
+	// 启动按主机调度的定期检查，首次检测时间在各自的间隔内分散
+	sched := scheduler.NewScheduler(chk, appCtx.Logger)
+	sched.Start(appCtx.Config)
+
+	// 启动配置文件监听
+	go config.WatchConfigFile(filepath.Join("configs", "config.yaml"), appCtx.Logger, func(newConfig *config.Config) {
 		// 保存旧的日志配置
 		oldLogConfig := appCtx.Config.Log
 
 		// 更新整个配置
 		appCtx.Config = newConfig
+		chk.UpdateConfig(newConfig)
+		consumer.UpdateConfig(newConfig)
+		reporter.UpdateConfig(newConfig)
 		appCtx.Logger.Log("Configuration reloaded successfully", "info")
 
 		// 如果日志配置发生变化，更新日志器
@@ -69,50 +86,10 @@ func main() {
 			appCtx.Logger.Log("Logger configuration updated", "info")
 		}
 
-		// 修改这部分，检查 ping.interval 和全局 interval 是否有变化
-		var newIntervalToUse int
-		if appCtx.Config.Ping.Interval > 0 {
-			newIntervalToUse = appCtx.Config.Ping.Interval
-		} else {
-			newIntervalToUse = appCtx.Config.Interval
-		}
-
-		var oldIntervalInUse int
-		if oldPingInterval > 0 {
-			oldIntervalInUse = oldPingInterval
-		} else {
-			oldIntervalInUse = oldInterval
-		}
-
-		// 如果实际使用的间隔时间发生变化，通知定时器更新
-		if oldIntervalInUse != newIntervalToUse {
-			appCtx.Logger.Log(fmt.Sprintf("Interval changed from %d to %d seconds", oldIntervalInUse, newIntervalToUse), "info")
-			tickerControlChan <- time.Duration(newIntervalToUse) * time.Second
-		}
+		// 同步主机列表和各主机的检测间隔
+		sched.UpdateConfig(newConfig)
 	})
 
-	// 然后初始化 pinger 和 checker
-	pinger := checker.NewPinger()
-	// 创建 AlertStatusManager
-	alertStatusManager, err := db.NewAlertStatusManager(appCtx.DB.Instance, appCtx.Logger, appCtx.Config.Db)
-	if err != nil {
-		appCtx.Logger.Fatal("Failed to create AlertStatusManager", "error")
-	}
-	appCtx.Logger.Log("AlertStatusManager initialized successfully", "debug")
-	appCtx.Logger.Log("Application started successfully", "info")
-
-	interval := time.Duration(appCtx.Config.Alert.AggregateWindow) * time.Second
-	consumer := notifier.NewConsumer(alertStatusManager, appCtx.Logger, interval, appCtx.AggregatorHandle)
-	go consumer.Start()
-
-	chk := checker.NewChecker(appCtx.Config, pinger, appCtx.Logger, alertStatusManager, appCtx.TSDB)
-	// 执行初始 ping 检查
-	appCtx.Logger.Log("Performing initial ping check", "info")
-	chk.PingHosts()
-
-	// 最后启动定期 ping 检查，并传入控制通道
-	stopChan := scheduler.StartPeriodicPingChecks(chk, appCtx.Config, appCtx.Logger, tickerControlChan)
-
 	// 等待退出信号
 	exitChan := signal.RegisterExitListener()
 	<-exitChan
@@ -121,7 +98,8 @@ func main() {
 	appCtx.Logger.Log("Received shutdown signal, starting graceful shutdown...", "info")
 	
 	// 停止定期检查
-	close(stopChan)
+	sched.Stop()
+	reporter.Stop()
 	
 	// 关闭 TSDB
 	if appCtx.TSDB != nil {
@@ -34,7 +34,13 @@ export const useHostStatusRefresh = (
         statusList.set(statusHost.host, {
           description: statusHost.host,
           latency: statusHost.avg_latency || null,
-          status: statusHost.status === "ALERT" ? "ALERT" : "RECOVERY",
+          status:
+            statusHost.status === "ALERT" ||
+            statusHost.status === "DEGRADED" ||
+            statusHost.status === "FLAPPING" ||
+            statusHost.status === "ACKNOWLEDGED"
+              ? statusHost.status
+              : "RECOVERY",
           sent: false,
         });
 
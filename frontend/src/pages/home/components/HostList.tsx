@@ -51,6 +51,15 @@ export function HostList({
     if (status?.status === "ALERT") {
       return "red";
     }
+    if (status?.status === "DEGRADED") {
+      return "orange";
+    }
+    if (status?.status === "FLAPPING") {
+      return "purple";
+    }
+    if (status?.status === "ACKNOWLEDGED") {
+      return "blue";
+    }
     return undefined;
   };
 
@@ -6,7 +6,7 @@ export interface Host {
 export interface HostStatus {
   description: string;
   latency: number | null; // 对应 tsdb 中的 avg_latency
-  status?: "ALERT" | "RECOVERY";
+  status?: "ALERT" | "DEGRADED" | "FLAPPING" | "ACKNOWLEDGED" | "RECOVERY";
   sent?: boolean;
 }
 
@@ -14,6 +14,7 @@ import (
 type Aggregator struct {
 	alertLineTemplate    string
 	recoveryLineTemplate string
+	degradedLineTemplate string
 	notifier             types.Notifier
 	logger               *logger.Logger
 	window               time.Duration
@@ -24,10 +25,11 @@ type Aggregator struct {
 // 确保 Aggregator 实现了 AggregatorHandle 接口
 var _ types.AggregatorHandle = (*Aggregator)(nil)
 
-func NewAggregator(alertLineTemplate string, recoveryLineTemplate string, notifier types.Notifier, logger *logger.Logger, window time.Duration) *Aggregator {
+func NewAggregator(alertLineTemplate string, recoveryLineTemplate string, degradedLineTemplate string, notifier types.Notifier, logger *logger.Logger, window time.Duration) *Aggregator {
 	return &Aggregator{
 		alertLineTemplate:    alertLineTemplate,
 		recoveryLineTemplate: recoveryLineTemplate,
+		degradedLineTemplate: degradedLineTemplate,
 		notifier:             notifier,
 		logger:               logger,
 		window:               window,
@@ -70,6 +72,9 @@ func (a *Aggregator) formatAlerts(alerts []*db.AlertStatus, isRecovery bool) (st
 	var template string
 	if isRecovery {
 		template = a.recoveryLineTemplate
+	} else if alerts[0].Status == db.StatusDegraded && !alerts[0].IsFlapNotice() && a.degradedLineTemplate != "" {
+		// 同一批次的状态相同，降级使用单独的行模板
+		template = a.degradedLineTemplate
 	} else {
 		template = a.alertLineTemplate
 	}
@@ -80,6 +85,8 @@ func (a *Aggregator) formatAlerts(alerts []*db.AlertStatus, isRecovery bool) (st
 		line = strings.ReplaceAll(line, "{{.RecoveryTime}}", alert.RecoveryTime)
 		line = strings.ReplaceAll(line, "{{.Host}}", alert.Host)
 		line = strings.ReplaceAll(line, "{{.Description}}", alert.Description)
+		line = strings.ReplaceAll(line, "{{.Reason}}", string(alert.Reason))
+		line = strings.ReplaceAll(line, "{{.Detail}}", alert.Detail)
 		alertList[i] = line
 	}
 	return strings.Join(alertList, "\n"), nil
@@ -5,36 +5,69 @@ import (
 	"easy-check/internal/db"
 	"easy-check/internal/logger"
 	"fmt"
+	"net"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Checker struct {
-	Config *config.Config
-	Pinger Pinger
-	Logger *logger.Logger
-	DB     *db.AlertStatusManager
-	TSDB   *db.TSDB
+	Config  *config.Config
+	Pinger  Pinger
+	Probers map[string]Prober
+	Logger  *logger.Logger
+	DB      *db.AlertStatusManager
+	TSDB    *db.TSDB
 	// 添加配置读写锁
 	configMu sync.RWMutex
+
+	// 限制同时进行的检测数量
+	poolMu sync.Mutex
+	pool   *WorkerPool
+
+	skipped atomic.Int64 // 因上一次检测未结束或队列已满而跳过的检测总数
 }
 
+// SelfMetricsHost 检测器自身指标（轮次耗时、跳过次数等）写入 TSDB 时使用的 host 标签
+const SelfMetricsHost = "_easy_check"
+
 func NewChecker(config *config.Config, pinger Pinger, logger *logger.Logger, db *db.AlertStatusManager, tsdb *db.TSDB) *Checker {
+	if db != nil {
+		db.SetFlapConfig(getFlapConfig(config))
+	}
 	return &Checker{
-		Config: config,
-		Pinger: pinger,
-		Logger: logger,
-		DB:     db,
-		TSDB:   tsdb,
+		Config:  config,
+		Pinger:  pinger,
+		Probers: NewProbers(),
+		Logger:  logger,
+		DB:      db,
+		TSDB:    tsdb,
 	}
 }
 
 // UpdateConfig 提供线程安全的配置更新方法
 func (c *Checker) UpdateConfig(newConfig *config.Config) {
 	c.configMu.Lock()
-	defer c.configMu.Unlock()
+	oldConfig := c.Config
 	c.Config = newConfig
+	c.configMu.Unlock()
+
+	if c.DB != nil {
+		c.DB.SetFlapConfig(getFlapConfig(newConfig))
+	}
+
+	// 并发数或队列长度变化时替换 worker 池，旧池在后台执行完已排队的检测
+	if getConcurrency(oldConfig) == getConcurrency(newConfig) && getQueueSize(oldConfig) == getQueueSize(newConfig) {
+		return
+	}
+	c.poolMu.Lock()
+	oldPool := c.pool
+	c.pool = nil
+	c.poolMu.Unlock()
+	if oldPool != nil {
+		go oldPool.Stop()
+	}
 }
 
 // getConfig 提供线程安全的配置读取
@@ -44,21 +77,44 @@ func (c *Checker) getConfig() *config.Config {
 	return c.Config
 }
 
-func (c *Checker) PingHosts() {
-	var wg sync.WaitGroup
-
-	// 使用线程安全的配置读取
-	cfg := c.getConfig()
+// SubmitCheck 将主机检测加入 worker 池队列，检测结束后调用 done
+// 队列已满时返回 false，此时不会调用 done
+func (c *Checker) SubmitCheck(host config.Host, done func()) bool {
+	return c.getPool().Submit(func() {
+		defer done()
+		c.CheckHost(host)
+	})
+}
 
-	for _, host := range cfg.Hosts {
-		wg.Add(1)
-		go func(host config.Host) {
-			defer wg.Done()
-			c.pingHost(host)
-		}(host)
+// RecordSkipped 记录被跳过的检测
+func (c *Checker) RecordSkipped(count int, reason string) {
+	if count <= 0 {
+		return
 	}
+	c.skipped.Add(int64(count))
+	c.Logger.Log(fmt.Sprintf("Skipped %d check(s): %s", count, reason), "warn")
+}
 
-	wg.Wait()
+// RecordCycle 将一轮检测的耗时、检测数、累计跳过数和队列长度写入 TSDB
+func (c *Checker) RecordCycle(duration time.Duration, checks int) {
+	c.Logger.Log(fmt.Sprintf("Check cycle of %d host(s) finished in %v", checks, duration), "debug")
+	c.writeMetricsToTSDB(SelfMetricsHost, nil, map[string]any{
+		"cycle_duration":       float64(duration.Milliseconds()),
+		"cycle_checks":         float64(checks),
+		"skipped_checks_total": float64(c.skipped.Load()),
+		"queue_length":         float64(c.getPool().QueueLength()),
+	})
+}
+
+// getPool 获取 worker 池，不存在时按当前配置创建
+func (c *Checker) getPool() *WorkerPool {
+	c.poolMu.Lock()
+	defer c.poolMu.Unlock()
+	if c.pool == nil {
+		cfg := c.getConfig()
+		c.pool = NewWorkerPool(getConcurrency(cfg), getQueueSize(cfg))
+	}
+	return c.pool
 }
 
 // 判断是否启用失败告警
@@ -77,85 +133,201 @@ type PingResult struct {
 	Color      string  `json:"color"`
 }
 
-func (c *Checker) pingHost(host config.Host) {
-	cfg := c.getConfig()
-	output, err := c.Pinger.Ping(host.Host, cfg.Ping.Count, cfg.Ping.Timeout)
+// CheckHost 根据主机的检测类型执行检测并处理结果
+func (c *Checker) CheckHost(host config.Host) {
+	pingCfg := c.getConfig().EffectivePingConfig(host)
+	hostType := host.GetType()
+
+	var result *ProbeResult
+	if hostType == config.HostTypePing {
+		result = c.pingHost(host, pingCfg)
+	} else if prober, ok := c.Probers[hostType]; ok {
+		if hostType == config.HostTypeTLS {
+			host.CertExpiryDays = c.getCertExpiryDays(host)
+		}
+		result = prober.Probe(host, pingCfg.Count, pingCfg.Timeout)
+	} else {
+		result = &ProbeResult{Count: pingCfg.Count, Err: fmt.Errorf("unsupported check type: %s", hostType)}
+	}
+
+	c.handleResult(host, hostType, result)
+}
+
+func (c *Checker) pingHost(host config.Host, pingCfg config.PingConfig) *ProbeResult {
+	ipVersion := resolveIPVersion(host.Host, pingCfg.IPVersion)
+	output, err := c.Pinger.Ping(host.Host, PingOptions{
+		Count:     pingCfg.Count,
+		Timeout:   pingCfg.Timeout,
+		Interval:  time.Duration(pingCfg.PacketInterval) * time.Millisecond,
+		IPVersion: ipVersion,
+	})
 
 	// 解析 Ping 输出
 	lines := strings.Split(output, "\n")
-	successCount, minLatency, avgLatency, maxLatency := c.Pinger.ParsePingOutput(lines, cfg.Ping.Count)
-	packetLossRate := c.calculatePacketLoss(successCount, cfg.Ping.Count)
+	successCount, latencies := c.Pinger.ParsePingOutput(lines, pingCfg.Count)
+
+	return &ProbeResult{
+		Count:        pingCfg.Count,
+		SuccessCount: successCount,
+		Latencies:    latencies,
+		Output:       output,
+		Err:          err,
+		IPVersion:    ipVersion,
+	}
+}
+
+// handleResult 根据检测结果处理告警/恢复逻辑并写入统计数据
+func (c *Checker) handleResult(host config.Host, hostType string, result *ProbeResult) {
+	packetLossRate := c.calculatePacketLoss(result.SuccessCount, result.Count)
+	stats := calculateLatencyStats(result.Latencies)
 
-	// 根据 Ping 结果处理逻辑
+	// 根据检测结果处理逻辑
 	var reason string
-	if err != nil {
-		reason = err.Error()
-	} else if packetLossRate > c.getFailRateThreshold() {
-		// 如果 Ping 失败或丢包率超过阈值，处理失败逻辑
+	reasonType := db.ReasonUnreachable
+	if result.Err != nil {
+		reason = result.Err.Error()
+		if result.Reason != "" {
+			reasonType = result.Reason
+		}
+	} else if packetLossRate > c.getFailRateThreshold(host) {
+		// 如果检测失败或丢包率超过阈值，处理失败逻辑
 		reason = fmt.Sprintf("packet loss rate %.2f%%", packetLossRate)
 	}
 
+	// 可达时再检查延迟阈值，超过 crit 告警，超过 warn 降级
+	var degradedReason string
+	if reason == "" && result.SuccessCount > 0 {
+		warn, crit, metric := c.getLatencyThresholds(host)
+		latency := stats.Avg
+		if metric == config.LatencyMetricP95 {
+			latency = stats.P95
+		}
+		if crit > 0 && latency > crit {
+			reasonType = db.ReasonHighLatency
+			reason = fmt.Sprintf("%s latency %.2fms exceeds critical threshold %.2fms", metric, latency, crit)
+		} else if warn > 0 && latency > warn {
+			degradedReason = fmt.Sprintf("%s latency %.2fms exceeds warning threshold %.2fms", metric, latency, warn)
+		}
+	}
+
 	if reason != "" {
 		// 记录失败日志
-		c.Logger.Log(fmt.Sprintf("Ping to [%s] %s failed: packet loss %.2f%%, avg latency time=%.2fms",
-			host.Description, host.Host, packetLossRate, avgLatency), "error")
-		c.handlePingFailure(host, reason, output)
+		c.Logger.Log(fmt.Sprintf("%s to [%s] %s failed: packet loss %.2f%%, avg latency time=%.2fms",
+			checkName(hostType), host.Description, host.Host, packetLossRate, stats.Avg), "error")
+		c.handlePingFailure(host, reasonType, reason, result.Output)
+	} else if degradedReason != "" {
+		c.Logger.Log(fmt.Sprintf("%s to [%s] %s degraded: %s",
+			checkName(hostType), host.Description, host.Host, degradedReason), "warn")
+		c.handleDegraded(host, degradedReason)
 	} else {
 		successRate := 100.0 - packetLossRate
-		c.Logger.Log(fmt.Sprintf("Ping to [%s] %s succeeded: success rate %.2f%%, latency time=%.2fms",
-			host.Description, host.Host, successRate, avgLatency), "info")
-		// 如果 Ping 成功且丢包率在阈值内，处理成功逻辑
+		c.Logger.Log(fmt.Sprintf("%s to [%s] %s succeeded: success rate %.2f%%, latency time=%.2fms",
+			checkName(hostType), host.Description, host.Host, successRate, stats.Avg), "info")
+		// 如果检测成功且丢包率在阈值内，处理成功逻辑
 		c.handlePingSuccess(host)
 	}
 
 	// 无论成功或失败，都写入统计数据到 TSDB
-	c.writeMetricsToTSDB(host.Host, map[string]any{
-		"packet_loss": packetLossRate,
-		"min_latency": minLatency,
-		"avg_latency": avgLatency,
-		"max_latency": maxLatency,
-	})
+	checkFailed := 0.0
+	if reason != "" {
+		checkFailed = 1 // 用于按检测结果计算可用率，降级不算失败
+	}
+	metrics := map[string]any{
+		"check_failed":   checkFailed,
+		"packet_loss":    packetLossRate,
+		"min_latency":    stats.Min,
+		"avg_latency":    stats.Avg,
+		"max_latency":    stats.Max,
+		"stddev_latency": stats.StdDev,
+		"jitter":         stats.Jitter,
+		"p50_latency":    stats.P50,
+		"p95_latency":    stats.P95,
+		"p99_latency":    stats.P99,
+	}
+	for key, value := range result.Metrics {
+		metrics[key] = value
+	}
+	labels := map[string]string{}
+	if result.IPVersion != "" {
+		labels["ip_version"] = result.IPVersion
+	}
+	c.writeMetricsToTSDB(host.Key(), labels, metrics)
 }
 
-func (c *Checker) handlePingFailure(host config.Host, reason string, output string) {
+// checkName 返回日志中使用的检测名称
+func checkName(hostType string) string {
+	if hostType == config.HostTypePing {
+		return "Ping"
+	}
+	return strings.ToUpper(hostType) + " check"
+}
+
+func (c *Checker) handlePingFailure(host config.Host, reasonType db.ReasonType, reason string, output string) {
 	// 检查是否启用失败告警
 	if !c.isFailAlertEnabled(host) {
 		c.Logger.Log(fmt.Sprintf("Fail alert disabled for host: %s", host.Host), "debug")
 		return
 	}
 
-	// 记录失败日志以及完整的ping输出
-	c.Logger.Log(fmt.Sprintf("Ping to [%s] %s failed: %s", host.Description, host.Host, reason), "error")
-	c.Logger.Log(fmt.Sprintf("Ping output for [%s] %s: \n%s", host.Description, host.Host, output), "info")
+	// 记录失败日志以及完整的检测输出
+	c.Logger.Log(fmt.Sprintf("%s to [%s] %s failed: %s", checkName(host.GetType()), host.Description, host.Host, reason), "error")
+	c.Logger.Log(fmt.Sprintf("%s output for [%s] %s: \n%s", checkName(host.GetType()), host.Description, host.Host, output), "info")
 
 	// 构造 AlertStatus 结构体
 	status := db.AlertStatus{
-		Host:         host.Host,
+		Host:         host.Key(),
 		Description:  host.Description,
 		Status:       db.StatusAlert,
 		FailTime:     time.Now().Format(time.RFC3339),
 		RecoveryTime: "",
 		FailAlert:    true,
 		Sent:         false,
+		Reason:       reasonType,
+		Detail:       reason,
 	}
 
-	// 将失败信息保存到数据库
-	err := c.DB.MarkAsAlert(status)
+	// 将失败信息保存到数据库，连续失败达到阈值后才告警
+	err := c.DB.RecordFailure(status, c.getFailThreshold(host))
 	if err != nil {
 		c.Logger.Log(fmt.Sprintf("Failed to record ping failure in DB: %v", err), "error")
 	}
 }
 
+// handleDegraded 处理延迟超过降级阈值的情况，主机仍视为可达
+func (c *Checker) handleDegraded(host config.Host, reason string) {
+	if !c.isFailAlertEnabled(host) {
+		c.Logger.Log(fmt.Sprintf("Fail alert disabled for host: %s", host.Host), "debug")
+		return
+	}
+
+	status := db.AlertStatus{
+		Host:        host.Key(),
+		Description: host.Description,
+		Status:      db.StatusDegraded,
+		FailTime:    time.Now().Format(time.RFC3339),
+		FailAlert:   true,
+		Sent:        false,
+		Reason:      db.ReasonHighLatency,
+		Detail:      reason,
+	}
+
+	err := c.DB.RecordFailure(status, c.getFailThreshold(host))
+	if err != nil {
+		c.Logger.Log(fmt.Sprintf("Failed to record degraded status in DB: %v", err), "error")
+	}
+}
+
 func (c *Checker) handlePingSuccess(host config.Host) {
 	// 构造 AlertStatus 结构体
 	status := db.AlertStatus{
-		Host:         host.Host,
+		Host:         host.Key(),
 		Description:  host.Description,
 		Status:       db.StatusRecovery,
 		RecoveryTime: time.Now().Format(time.RFC3339),
 	}
 
-	err := c.DB.MarkAsRecovered(status)
+	// 连续成功达到阈值后才恢复
+	err := c.DB.RecordSuccess(status, c.getRecoveryThreshold(host))
 	if err != nil {
 		c.Logger.Log(fmt.Sprintf("Failed to update host recovery status: %v", err), "error")
 	}
@@ -166,10 +338,13 @@ func (c *Checker) calculatePacketLoss(successCount, totalCount int) float64 {
 }
 
 // 将统计数据写入 TSDB
-func (c *Checker) writeMetricsToTSDB(host string, metrics map[string]interface{}) {
+func (c *Checker) writeMetricsToTSDB(host string, extraLabels map[string]string, metrics map[string]interface{}) {
 	labels := map[string]string{
 		"host": host,
 	}
+	for key, value := range extraLabels {
+		labels[key] = value
+	}
 
 	// 转换 metrics 数据类型为 map[string]float64
 	floatMetrics := make(map[string]float64)
@@ -191,19 +366,130 @@ func (c *Checker) writeMetricsToTSDB(host string, metrics map[string]interface{}
 	}
 }
 
-func (c *Checker) getFailRateThreshold() float64 {
+// getFailRateThreshold 获取主机生效的失败率阈值（百分比），主机配置优先
+func (c *Checker) getFailRateThreshold(host config.Host) float64 {
+	// 如果配置的是小数形式（如0.2表示20%），则乘以100转换为百分比
+	// 如果配置的已经是百分比形式（如20表示20%），则直接使用
+	lossRate := c.getConfig().EffectivePingConfig(host).LossRate
+	if lossRate <= 1.0 {
+		// 小数形式，需要转换为百分比
+		return lossRate * 100
+	}
+	// 已经是百分比形式，直接使用
+	return lossRate
+}
+
+// getFailThreshold 获取连续失败多少次后告警，主机配置优先
+func (c *Checker) getFailThreshold(host config.Host) int {
+	if host.FailThreshold > 0 {
+		return host.FailThreshold
+	}
+	cfg := c.getConfig()
+	if cfg.Alert.FailThreshold > 0 {
+		return cfg.Alert.FailThreshold
+	}
+	return 1 // 默认值（失败一次即告警）
+}
+
+// getRecoveryThreshold 获取连续成功多少次后恢复，主机配置优先
+func (c *Checker) getRecoveryThreshold(host config.Host) int {
+	if host.RecoveryThreshold > 0 {
+		return host.RecoveryThreshold
+	}
 	cfg := c.getConfig()
-	if cfg.Ping.LossRate > 0 {
-		// 如果配置的是小数形式（如0.2表示20%），则乘以100转换为百分比
-		// 如果配置的已经是百分比形式（如20表示20%），则直接使用
-		lossRate := float64(cfg.Ping.LossRate)
-		if lossRate <= 1.0 {
-			// 小数形式，需要转换为百分比
-			return lossRate * 100
-		} else {
-			// 已经是百分比形式，直接使用
-			return lossRate
+	if cfg.Alert.RecoveryThreshold > 0 {
+		return cfg.Alert.RecoveryThreshold
+	}
+	return 1 // 默认值（成功一次即恢复）
+}
+
+// getFlapConfig 获取抖动检测配置
+func getFlapConfig(cfg *config.Config) db.FlapConfig {
+	window := 600 // 默认值（10 分钟）
+	if cfg.Alert.FlapWindow > 0 {
+		window = cfg.Alert.FlapWindow
+	}
+	return db.FlapConfig{
+		Window:    time.Duration(window) * time.Second,
+		Threshold: cfg.Alert.FlapThreshold,
+	}
+}
+
+// getConcurrency 获取同时进行的检测数量上限
+func getConcurrency(cfg *config.Config) int {
+	if cfg != nil && cfg.Concurrency > 0 {
+		return cfg.Concurrency
+	}
+	return 32 // 默认值
+}
+
+// getQueueSize 获取等待检测的队列长度
+func getQueueSize(cfg *config.Config) int {
+	if cfg != nil && cfg.QueueSize > 0 {
+		return cfg.QueueSize
+	}
+	return 1024 // 默认值
+}
+
+// getCertExpiryDays 获取证书过期告警阈值（天），主机配置优先
+func (c *Checker) getCertExpiryDays(host config.Host) int {
+	if host.CertExpiryDays > 0 {
+		return host.CertExpiryDays
+	}
+	cfg := c.getConfig()
+	if cfg.Alert.CertExpiryDays > 0 {
+		return cfg.Alert.CertExpiryDays
+	}
+	return 14 // 默认值（证书剩余不足 14 天触发告警）
+}
+
+// getLatencyThresholds 获取延迟降级/告警阈值（毫秒）及比较的指标，主机配置优先
+func (c *Checker) getLatencyThresholds(host config.Host) (float64, float64, string) {
+	cfg := c.getConfig()
+	warn, crit, metric := host.LatencyWarn, host.LatencyCrit, host.LatencyMetric
+	if warn <= 0 {
+		warn = cfg.Alert.LatencyWarn
+	}
+	if crit <= 0 {
+		crit = cfg.Alert.LatencyCrit
+	}
+	if metric == "" {
+		metric = cfg.Alert.LatencyMetric
+	}
+	if metric != config.LatencyMetricP95 {
+		metric = config.LatencyMetricAvg
+	}
+	return warn, crit, metric
+}
+
+// resolveIPVersion 确定本次检测实际使用的地址族，auto 时优先使用 IPv4
+func resolveIPVersion(host string, ipVersion string) string {
+	switch ipVersion {
+	case config.IPVersion4, config.IPVersion6:
+		return ipVersion
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.To4() != nil {
+			return config.IPVersion4
 		}
+		return config.IPVersion6
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		// 解析失败时交给 ping 报错
+		return config.IPVersion4
+	}
+	hasIPv6 := false
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			return config.IPVersion4
+		}
+		hasIPv6 = true
+	}
+	if hasIPv6 {
+		return config.IPVersion6
 	}
-	return 20.0 // 默认值（失败率超过 20% 触发告警）
+	return config.IPVersion4
 }
@@ -0,0 +1,96 @@
+package checker
+
+import (
+	"easy-check/internal/config"
+	"testing"
+)
+
+func TestGetLatencyThresholds(t *testing.T) {
+	c := &Checker{Config: &config.Config{
+		Alert: config.AlertConfig{LatencyWarn: 200, LatencyCrit: 500},
+	}}
+
+	tests := []struct {
+		name       string
+		host       config.Host
+		wantWarn   float64
+		wantCrit   float64
+		wantMetric string
+	}{
+		{
+			name:       "global",
+			host:       config.Host{Host: "a"},
+			wantWarn:   200,
+			wantCrit:   500,
+			wantMetric: config.LatencyMetricAvg,
+		},
+		{
+			name:       "host override",
+			host:       config.Host{Host: "b", LatencyWarn: 300, LatencyMetric: "p95"},
+			wantWarn:   300,
+			wantCrit:   500,
+			wantMetric: config.LatencyMetricP95,
+		},
+		{
+			name:       "unknown metric falls back to avg",
+			host:       config.Host{Host: "c", LatencyMetric: "p42"},
+			wantWarn:   200,
+			wantCrit:   500,
+			wantMetric: config.LatencyMetricAvg,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warn, crit, metric := c.getLatencyThresholds(tt.host)
+			if warn != tt.wantWarn || crit != tt.wantCrit || metric != tt.wantMetric {
+				t.Errorf("got (%v, %v, %q), want (%v, %v, %q)", warn, crit, metric, tt.wantWarn, tt.wantCrit, tt.wantMetric)
+			}
+		})
+	}
+}
+
+func TestGetFailRateThreshold(t *testing.T) {
+	c := &Checker{Config: &config.Config{Ping: config.PingConfig{LossRate: 0.2}}}
+
+	tests := []struct {
+		name string
+		host config.Host
+		want float64
+	}{
+		{name: "global", host: config.Host{Host: "a"}, want: 20},
+		{name: "host fraction", host: config.Host{Host: "b", LossRate: 0.05}, want: 5},
+		{name: "host percent", host: config.Host{Host: "c", LossRate: 50}, want: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.getFailRateThreshold(tt.host); got != tt.want {
+				t.Errorf("getFailRateThreshold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveIPVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		host      string
+		ipVersion string
+		want      string
+	}{
+		{name: "auto ipv4 literal", host: "192.0.2.1", ipVersion: config.IPVersionAuto, want: config.IPVersion4},
+		{name: "auto ipv6 literal", host: "2001:db8::1", ipVersion: config.IPVersionAuto, want: config.IPVersion6},
+		{name: "auto ipv4-mapped ipv6 literal", host: "::ffff:192.0.2.1", ipVersion: config.IPVersionAuto, want: config.IPVersion4},
+		{name: "forced ipv4", host: "2001:db8::1", ipVersion: config.IPVersion4, want: config.IPVersion4},
+		{name: "forced ipv6", host: "192.0.2.1", ipVersion: config.IPVersion6, want: config.IPVersion6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveIPVersion(tt.host, tt.ipVersion); got != tt.want {
+				t.Errorf("resolveIPVersion(%q, %q) = %q, want %q", tt.host, tt.ipVersion, got, tt.want)
+			}
+		})
+	}
+}
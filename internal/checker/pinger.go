@@ -1,12 +1,22 @@
 package checker
 
+import "time"
+
+// PingOptions 单次 ping 检测的参数
+type PingOptions struct {
+	Count     int           // 发包数量
+	Timeout   int           // 单个包的超时（秒）
+	Interval  time.Duration // 发包间隔
+	IPVersion string        // 地址族 "4" 或 "6"
+}
+
 // Pinger 定义了ping主机的接口
 type Pinger interface {
 	// Ping 执行ping操作，返回输出结果，错误
-	Ping(host string, count int, timeout int) (string, error)
+	Ping(host string, opts PingOptions) (string, error)
 
-	// ParsePingOutput 解析ping输出，返回成功次数和最小、平均、最大延迟
-	ParsePingOutput(lines []string, count int) (int, float64, float64, float64)
+	// ParsePingOutput 解析ping输出，返回成功次数和每个回复的往返时间（毫秒）
+	ParsePingOutput(lines []string, count int) (int, []float64)
 }
 
 // NewPinger 函数在相应的平台特定文件中实现
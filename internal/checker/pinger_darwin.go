@@ -13,31 +13,37 @@ import (
 
 type DarwinPinger struct{}
 
-func (p *DarwinPinger) Ping(host string, count int, timeout int) (string, error) {
-	// macOS 使用的 ping 命令参数
-	cmd := exec.Command("ping", "-c", fmt.Sprintf("%d", count), "-W", fmt.Sprintf("%d", timeout), host)
+func (p *DarwinPinger) Ping(host string, opts PingOptions) (string, error) {
+	// macOS 使用的 ping 命令参数，IPv6 需要使用 ping6（不支持 -W）
+	args := []string{"-c", fmt.Sprintf("%d", opts.Count)}
+	if opts.Interval > 0 {
+		args = append(args, "-i", fmt.Sprintf("%.3f", opts.Interval.Seconds()))
+	}
+	cmd := exec.Command("ping", append(args, "-W", fmt.Sprintf("%d", opts.Timeout), host)...)
+	if opts.IPVersion == "6" {
+		cmd = exec.Command("ping6", append(args, host)...)
+	}
 	output, err := cmd.CombinedOutput()
 	return string(output), err
 }
 
-func (p *DarwinPinger) ParsePingOutput(lines []string, count int) (int, float64, float64, float64) {
+func (p *DarwinPinger) ParsePingOutput(lines []string, count int) (int, []float64) {
 	var successCount int
-	var minLatency, avgLatency, maxLatency float64
+	var latencies []float64
 
-	// 使用正则表达式解析 ping 输出
-	latencyRegex := regexp.MustCompile(`min/avg/max/.+=\s*([\d.]+)/([\d.]+)/([\d.]+)`)
+	// 使用正则表达式解析每个回复的延迟
+	latencyRegex := regexp.MustCompile(`time=([\d.]+) ms`)
 	for _, line := range lines {
 		if strings.Contains(line, "bytes from") {
 			successCount++
-		}
-		if matches := latencyRegex.FindStringSubmatch(line); matches != nil {
-			minLatency, _ = strconv.ParseFloat(matches[1], 64)
-			avgLatency, _ = strconv.ParseFloat(matches[2], 64)
-			maxLatency, _ = strconv.ParseFloat(matches[3], 64)
+			if matches := latencyRegex.FindStringSubmatch(line); matches != nil {
+				latency, _ := strconv.ParseFloat(matches[1], 64)
+				latencies = append(latencies, latency)
+			}
 		}
 	}
 
-	return successCount, minLatency, avgLatency, maxLatency
+	return successCount, latencies
 }
 
 func NewPinger() Pinger {
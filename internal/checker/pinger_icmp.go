@@ -4,70 +4,311 @@ import (
 	"bytes"
 	"fmt"
 	"net"
+	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/net/icmp"
 	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
 )
 
-type ICMPPinger struct{}
+// ICMPPinger 直接收发 ICMP 报文，不依赖系统 ping 命令的输出格式
+// Privileged 为 true 时使用原始套接字（需要 root/管理员），
+// 为 false 时使用 udp4/udp6 ICMP 数据报套接字（Linux 需 net.ipv4.ping_group_range 包含当前用户组）
+//
+// 所有主机共用每个地址族的一个监听套接字，回复按 seq 分发给对应的请求，
+// 因此多个主机可以同时 ping，且同一主机的多个包无需等待上一个回复
+type ICMPPinger struct {
+	Privileged bool
 
-func (p *ICMPPinger) Ping(host string, count int, timeout int) (string, error) {
-	ipAddr, err := net.ResolveIPAddr("ip4", host)
+	mu        sync.Mutex
+	listeners map[string]*icmpListener // key 为地址族 "4"/"6"
+}
+
+// icmpListener 共享的 ICMP 套接字，负责读取回复并分发给等待中的请求
+type icmpListener struct {
+	conn      *icmp.PacketConn
+	network   string // 监听的网络类型，如 ip4:icmp、udp6
+	address   string // 监听地址
+	protocol  int
+	echoType  icmp.Type
+	replyType icmp.Type
+	id        int  // 原始套接字下用于过滤其他进程的回复
+	checkID   bool // 数据报套接字的 ID 由内核改写，无需检查
+
+	mu      sync.Mutex
+	seq     int
+	pending map[int]*pendingEcho
+	closed  bool
+}
+
+// pendingEcho 已发送、等待回复的 echo 请求
+type pendingEcho struct {
+	index int
+	dst   net.IP
+	sent  time.Time
+	reply chan<- echoReply
+}
+
+// echoReply 某个请求收到回复的时间
+type echoReply struct {
+	index    int
+	received time.Time
+}
+
+func (p *ICMPPinger) Ping(host string, opts PingOptions) (string, error) {
+	if opts.Count <= 0 {
+		return "", fmt.Errorf("invalid ping count: %d", opts.Count)
+	}
+
+	network := "ip4"
+	if opts.IPVersion == "6" {
+		network = "ip6"
+	}
+	ipAddr, err := net.ResolveIPAddr(network, host)
 	if err != nil {
 		return "", fmt.Errorf("failed to resolve host: %v", err)
 	}
 
-	conn, err := icmp.ListenPacket("ip4:icmp", "")
+	listener, err := p.getListener(opts.IPVersion)
 	if err != nil {
 		return "", fmt.Errorf("failed to listen for ICMP packets: %v", err)
 	}
-	defer conn.Close()
 
-	var buffer bytes.Buffer
-	for i := 0; i < count; i++ {
-		msg := icmp.Message{
-			Type: ipv4.ICMPTypeEcho,
-			Code: 0,
-			Body: &icmp.Echo{
-				ID:   i + 1,
-				Seq:  i + 1,
-				Data: []byte("PING"),
-			},
+	// 数据报套接字的目标地址需要是 UDPAddr
+	var dst net.Addr = ipAddr
+	if !p.Privileged {
+		dst = &net.UDPAddr{IP: ipAddr.IP, Zone: ipAddr.Zone}
+	}
+
+	timeout := time.Duration(opts.Timeout) * time.Second
+	replies := make(chan echoReply, opts.Count)
+	sent := make([]time.Time, opts.Count)
+	seqs := make([]int, 0, opts.Count) // 已成功发送的包的 seq
+	var sendErr error
+
+	// 按间隔连续发包，不等待上一个包的回复
+	for i := 0; i < opts.Count; i++ {
+		if i > 0 && opts.Interval > 0 {
+			time.Sleep(opts.Interval)
 		}
-		msgBytes, err := msg.Marshal(nil)
+		pending := &pendingEcho{index: i, dst: ipAddr.IP, sent: time.Now(), reply: replies}
+		sent[i] = pending.sent
+		// 发送失败时 send 已移除该 seq
+		seq, err := listener.send(pending, dst)
 		if err != nil {
-			return "", fmt.Errorf("failed to marshal ICMP message: %v", err)
+			sendErr = fmt.Errorf("failed to send ICMP message: %v", err)
+			break
 		}
+		seqs = append(seqs, seq)
+	}
 
-		start := time.Now()
-		_, err = conn.WriteTo(msgBytes, ipAddr)
-		if err != nil {
-			return "", fmt.Errorf("failed to send ICMP message: %v", err)
+	// 等待所有回复，最多等到最后一个包超时
+	received := make([]time.Time, opts.Count)
+	remaining := opts.Count
+	if sendErr != nil {
+		remaining = 0
+	}
+	deadline := time.NewTimer(time.Until(sent[len(sent)-1].Add(timeout)))
+	defer deadline.Stop()
+	for remaining > 0 {
+		select {
+		case r := <-replies:
+			received[r.index] = r.received
+			remaining--
+		case <-deadline.C:
+			remaining = 0
 		}
+	}
+	// 只移除本次发出的 seq，共享监听器中的其他 seq 可能属于并发检测的其他主机
+	for _, seq := range seqs {
+		listener.unregister(seq)
+	}
+	if sendErr != nil {
+		return "", sendErr
+	}
 
-		conn.SetReadDeadline(time.Now().Add(time.Duration(timeout) * time.Second))
-		reply := make([]byte, 1500)
-		_, _, err = conn.ReadFrom(reply)
-		if err != nil {
+	// 按回复时间计算每个包的往返时间，超过超时时间的回复视为丢失
+	var buffer bytes.Buffer
+	for i := 0; i < opts.Count; i++ {
+		rtt := received[i].Sub(sent[i])
+		if received[i].IsZero() || rtt > timeout {
 			buffer.WriteString(fmt.Sprintf("Request timeout for icmp_seq %d\n", i+1))
-		} else {
-			duration := time.Since(start)
-			buffer.WriteString(fmt.Sprintf("Reply from %s: time=%v\n", ipAddr.String(), duration))
+			continue
 		}
+		// 统一以毫秒输出，避免亚毫秒延迟输出为 µs 后无法解析
+		buffer.WriteString(fmt.Sprintf("Reply from %s: icmp_seq=%d time=%.3fms\n", ipAddr.String(), i+1, float64(rtt.Microseconds())/1000))
+	}
+
+	return buffer.String(), nil
+}
+
+// getListener 获取地址族对应的共享套接字，不存在或已关闭时重新创建
+func (p *ICMPPinger) getListener(ipVersion string) (*icmpListener, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.listeners == nil {
+		p.listeners = make(map[string]*icmpListener)
+	}
+	if l, ok := p.listeners[ipVersion]; ok && !l.isClosed() {
+		return l, nil
+	}
+
+	l := newICMPListener(ipVersion, p.Privileged)
+	conn, err := icmp.ListenPacket(l.network, l.address)
+	if err != nil {
+		return nil, err
+	}
+	l.conn = conn
+	go l.readLoop()
 
-		// 等待 1 秒再发送下一个包
-		if i < count-1 {
-			time.Sleep(1 * time.Second)
+	p.listeners[ipVersion] = l
+	return l, nil
+}
+
+// newICMPListener 根据地址族和权限确定套接字类型和报文类型，尚未打开套接字
+// 有特权时使用原始套接字；否则使用 udp4/udp6 数据报套接字，其 echo ID 由内核改写，不检查 ID
+func newICMPListener(ipVersion string, privileged bool) *icmpListener {
+	l := &icmpListener{
+		network:   "ip4:icmp",
+		protocol:  1,
+		echoType:  ipv4.ICMPTypeEcho,
+		replyType: ipv4.ICMPTypeEchoReply,
+		id:        os.Getpid() & 0xffff,
+		checkID:   privileged,
+		pending:   make(map[int]*pendingEcho),
+	}
+	if ipVersion == "6" {
+		l.network, l.protocol, l.echoType, l.replyType = "ip6:ipv6-icmp", 58, ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+	if !privileged {
+		l.network, l.address = "udp4", "0.0.0.0"
+		if ipVersion == "6" {
+			l.network, l.address = "udp6", "::"
 		}
 	}
+	return l
+}
 
-	return buffer.String(), nil
+// send 分配 seq 并发送 echo 请求
+func (l *icmpListener) send(pending *pendingEcho, dst net.Addr) (int, error) {
+	l.mu.Lock()
+	// 跳过仍在等待回复的 seq，避免回绕后冲突
+	for {
+		l.seq = (l.seq + 1) & 0xffff
+		if _, exists := l.pending[l.seq]; !exists {
+			break
+		}
+	}
+	seq := l.seq
+	l.pending[seq] = pending
+	l.mu.Unlock()
+
+	msg := icmp.Message{
+		Type: l.echoType,
+		Code: 0,
+		Body: &icmp.Echo{
+			ID:   l.id,
+			Seq:  seq,
+			Data: []byte("PING"),
+		},
+	}
+	msgBytes, err := msg.Marshal(nil)
+	if err == nil {
+		_, err = l.conn.WriteTo(msgBytes, dst)
+	}
+	if err != nil {
+		l.unregister(seq)
+		return seq, err
+	}
+	return seq, nil
+}
+
+// unregister 移除等待中的请求
+func (l *icmpListener) unregister(seq int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.pending, seq)
 }
 
-func (p *ICMPPinger) ParsePingOutput(lines []string, count int) (int, float64, float64, float64) {
+func (l *icmpListener) isClosed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.closed
+}
+
+// readLoop 持续读取回复，记录收到的时间并按 seq 分发
+func (l *icmpListener) readLoop() {
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := l.conn.ReadFrom(buf)
+		received := time.Now()
+		if err != nil {
+			// 套接字出错后关闭，下次 Ping 时重新创建
+			l.mu.Lock()
+			l.closed = true
+			l.mu.Unlock()
+			l.conn.Close()
+			return
+		}
+
+		seq, ok := l.parseEchoReply(buf[:n])
+		if !ok {
+			continue
+		}
+
+		l.mu.Lock()
+		pending, ok := l.pending[seq]
+		if ok && pending.dst.Equal(peerIP(peer)) {
+			delete(l.pending, seq)
+			pending.reply <- echoReply{index: pending.index, received: received}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// parseEchoReply 解析收到的报文，只接受发给本进程的 echo reply，返回其 seq
+// IPv6 下会收到邻居发现等其他 ICMPv6 报文，需要过滤；
+// 数据报套接字的 echo ID 会被内核改写为本地端口，因此只按 seq 匹配
+func (l *icmpListener) parseEchoReply(data []byte) (int, bool) {
+	msg, err := icmp.ParseMessage(l.protocol, data)
+	if err != nil || msg.Type != l.replyType {
+		return 0, false
+	}
+	echo, ok := msg.Body.(*icmp.Echo)
+	if !ok || (l.checkID && echo.ID != l.id) {
+		return 0, false
+	}
+	return echo.Seq, true
+}
+
+// peerIP 提取回复来源的 IP 地址
+func peerIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.IPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	}
+	return nil
+}
+
+// canUseICMPDatagram 检查当前用户能否创建 ICMP 数据报套接字
+func canUseICMPDatagram() bool {
+	l := newICMPListener("4", false)
+	conn, err := icmp.ListenPacket(l.network, l.address)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+func (p *ICMPPinger) ParsePingOutput(lines []string, count int) (int, []float64) {
 	successCount := 0
 	var latencies []float64
 
@@ -95,26 +336,5 @@ func (p *ICMPPinger) ParsePingOutput(lines []string, count int) (int, float64, f
 		}
 	}
 
-	// 如果没有延迟值，返回默认值
-	if len(latencies) == 0 {
-		return successCount, 0, 0, 0
-	}
-
-	// 计算最小、最大和平均延迟
-	minLatency := latencies[0]
-	maxLatency := latencies[0]
-	var totalLatency float64
-
-	for _, latency := range latencies {
-		if latency < minLatency {
-			minLatency = latency
-		}
-		if latency > maxLatency {
-			maxLatency = latency
-		}
-		totalLatency += latency
-	}
-
-	avgLatency := totalLatency / float64(len(latencies))
-	return successCount, minLatency, avgLatency, maxLatency
+	return successCount, latencies
 }
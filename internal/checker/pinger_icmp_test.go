@@ -0,0 +1,159 @@
+package checker
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// newTestICMPPinger 返回当前环境可用的 ICMPPinger，均不可用时跳过测试
+func newTestICMPPinger(t *testing.T) *ICMPPinger {
+	for _, privileged := range []bool{true, false} {
+		p := &ICMPPinger{Privileged: privileged}
+		if _, err := p.getListener("4"); err == nil {
+			return p
+		}
+	}
+	t.Skip("ICMP sockets are not available in this environment")
+	return nil
+}
+
+func TestICMPPingerConcurrent(t *testing.T) {
+	pinger := newTestICMPPinger(t)
+	opts := PingOptions{Count: 5, Timeout: 1, Interval: 10 * time.Millisecond, IPVersion: "4"}
+
+	// 多个主机同时 ping 共用同一个套接字，回复不能串到其他请求
+	var wg sync.WaitGroup
+	results := make([]int, 20)
+	errs := make([]error, len(results))
+	start := time.Now()
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			output, err := pinger.Ping("127.0.0.1", opts)
+			errs[i] = err
+			results[i], _ = pinger.ParsePingOutput(strings.Split(output, "\n"), opts.Count)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatalf("Ping() err = %v", errs[i])
+		}
+		if results[i] != opts.Count {
+			t.Errorf("ping %d: success count = %d, want %d", i, results[i], opts.Count)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("concurrent pings took %v, expected them to run in parallel", elapsed)
+	}
+}
+
+func TestParseEchoReplyFiltersICMPv6(t *testing.T) {
+	l := newICMPListener("6", true)
+	marshal := func(msg icmp.Message) []byte {
+		data, err := msg.Marshal(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantSeq int
+		wantOK  bool
+	}{
+		{
+			name:    "echo reply",
+			data:    marshal(icmp.Message{Type: ipv6.ICMPTypeEchoReply, Body: &icmp.Echo{ID: l.id, Seq: 7}}),
+			wantSeq: 7,
+			wantOK:  true,
+		},
+		{
+			// 原始套接字也会收到本机发出的 echo request
+			name: "echo request",
+			data: marshal(icmp.Message{Type: ipv6.ICMPTypeEchoRequest, Body: &icmp.Echo{ID: l.id, Seq: 7}}),
+		},
+		{
+			name: "neighbor solicitation",
+			data: marshal(icmp.Message{Type: ipv6.ICMPTypeNeighborSolicitation, Body: &icmp.DefaultMessageBody{Data: make([]byte, 20)}}),
+		},
+		{
+			name: "router advertisement",
+			data: marshal(icmp.Message{Type: ipv6.ICMPTypeRouterAdvertisement, Body: &icmp.DefaultMessageBody{Data: make([]byte, 12)}}),
+		},
+		{
+			name: "destination unreachable",
+			data: marshal(icmp.Message{Type: ipv6.ICMPTypeDestinationUnreachable, Body: &icmp.DstUnreach{Data: make([]byte, 48)}}),
+		},
+		{
+			name: "echo reply to another process",
+			data: marshal(icmp.Message{Type: ipv6.ICMPTypeEchoReply, Body: &icmp.Echo{ID: l.id + 1, Seq: 7}}),
+		},
+		{
+			name: "truncated packet",
+			data: []byte{129},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seq, ok := l.parseEchoReply(tt.data)
+			if ok != tt.wantOK || seq != tt.wantSeq {
+				t.Errorf("parseEchoReply() = (%d, %v), want (%d, %v)", seq, ok, tt.wantSeq, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNewICMPListener(t *testing.T) {
+	tests := []struct {
+		ipVersion   string
+		privileged  bool
+		wantNetwork string
+		wantAddress string
+		wantCheckID bool
+	}{
+		{ipVersion: "4", privileged: true, wantNetwork: "ip4:icmp", wantCheckID: true},
+		{ipVersion: "6", privileged: true, wantNetwork: "ip6:ipv6-icmp", wantCheckID: true},
+		{ipVersion: "4", privileged: false, wantNetwork: "udp4", wantAddress: "0.0.0.0"},
+		{ipVersion: "6", privileged: false, wantNetwork: "udp6", wantAddress: "::"},
+	}
+
+	for _, tt := range tests {
+		l := newICMPListener(tt.ipVersion, tt.privileged)
+		if l.network != tt.wantNetwork || l.address != tt.wantAddress || l.checkID != tt.wantCheckID {
+			t.Errorf("newICMPListener(%q, %v) = (%q, %q, checkID=%v), want (%q, %q, checkID=%v)",
+				tt.ipVersion, tt.privileged, l.network, l.address, l.checkID, tt.wantNetwork, tt.wantAddress, tt.wantCheckID)
+		}
+	}
+}
+
+func TestParseEchoReplyDatagramIgnoresID(t *testing.T) {
+	// 数据报套接字的回复中 ID 为内核分配的本地端口，与进程号无关，只按 seq 匹配
+	reply, err := (&icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: &icmp.Echo{ID: 40000, Seq: 3}}).Marshal(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	datagram := newICMPListener("4", false)
+	datagram.id = 1
+	if seq, ok := datagram.parseEchoReply(reply); !ok || seq != 3 {
+		t.Errorf("datagram listener: parseEchoReply() = (%d, %v), want (3, true)", seq, ok)
+	}
+
+	privileged := newICMPListener("4", true)
+	privileged.id = 1
+	if _, ok := privileged.parseEchoReply(reply); ok {
+		t.Error("privileged listener accepted a reply with another echo ID")
+	}
+}
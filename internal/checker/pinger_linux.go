@@ -14,13 +14,23 @@ import (
 
 type LinuxPinger struct{}
 
-func (p *LinuxPinger) Ping(host string, count int, timeout int) (string, error) {
-	cmd := exec.Command("ping", "-4", "-c", fmt.Sprintf("%d", count), "-W", fmt.Sprintf("%d", timeout), host)
+func (p *LinuxPinger) Ping(host string, opts PingOptions) (string, error) {
+	cmd := exec.Command("ping", linuxPingArgs(host, opts)...)
 	output, err := cmd.CombinedOutput()
 	return string(output), err
 }
 
-func (p *LinuxPinger) ParsePingOutput(lines []string, count int) (int, float64, float64, float64) {
+// linuxPingArgs 生成 ping 命令的参数，通过 -4/-6 指定地址族
+func linuxPingArgs(host string, opts PingOptions) []string {
+	args := []string{"-" + opts.IPVersion, "-c", fmt.Sprintf("%d", opts.Count), "-W", fmt.Sprintf("%d", opts.Timeout)}
+	if opts.Interval > 0 {
+		// 非 root 用户的最小间隔为 0.2 秒
+		args = append(args, "-i", fmt.Sprintf("%.3f", opts.Interval.Seconds()))
+	}
+	return append(args, host)
+}
+
+func (p *LinuxPinger) ParsePingOutput(lines []string, count int) (int, []float64) {
 	successCount := 0
 	var latencies []float64
 
@@ -38,33 +48,21 @@ func (p *LinuxPinger) ParsePingOutput(lines []string, count int) (int, float64,
 		}
 	}
 
-	// 如果没有延迟值，返回默认值
-	if len(latencies) == 0 {
-		return successCount, 0, 0, 0
-	}
-
-	// 计算最小、最大和平均延迟
-	minLatency := latencies[0]
-	maxLatency := latencies[0]
-	var totalLatency float64
-
-	for _, latency := range latencies {
-		if latency < minLatency {
-			minLatency = latency
-		}
-		if latency > maxLatency {
-			maxLatency = latency
-		}
-		totalLatency += latency
-	}
-
-	avgLatency := totalLatency / float64(len(latencies))
-	return successCount, minLatency, avgLatency, maxLatency
+	return successCount, latencies
 }
 
 func NewPinger() Pinger {
-	if isAdmin() {
-		return &ICMPPinger{}
+	admin := isAdmin()
+	return selectPinger(admin, !admin && canUseICMPDatagram())
+}
+
+// selectPinger 依次选择原始套接字、ICMP 数据报套接字，最后才回退到执行 ping 命令
+func selectPinger(admin, datagram bool) Pinger {
+	if admin {
+		return &ICMPPinger{Privileged: true}
+	}
+	if datagram {
+		return &ICMPPinger{Privileged: false}
 	}
 	return &LinuxPinger{}
 }
@@ -0,0 +1,61 @@
+//go:build linux
+// +build linux
+
+package checker
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLinuxPingArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		opts PingOptions
+		want []string
+	}{
+		{
+			name: "ipv4",
+			host: "192.0.2.1",
+			opts: PingOptions{Count: 3, Timeout: 2, IPVersion: "4"},
+			want: []string{"-4", "-c", "3", "-W", "2", "192.0.2.1"},
+		},
+		{
+			name: "ipv6 with interval",
+			host: "2001:db8::1",
+			opts: PingOptions{Count: 5, Timeout: 1, Interval: 200 * time.Millisecond, IPVersion: "6"},
+			want: []string{"-6", "-c", "5", "-W", "1", "-i", "0.200", "2001:db8::1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linuxPingArgs(tt.host, tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("linuxPingArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectPinger(t *testing.T) {
+	tests := []struct {
+		name     string
+		admin    bool
+		datagram bool
+		want     Pinger
+	}{
+		{name: "root uses raw socket", admin: true, datagram: true, want: &ICMPPinger{Privileged: true}},
+		{name: "datagram socket allowed", admin: false, datagram: true, want: &ICMPPinger{Privileged: false}},
+		{name: "fallback to ping command", admin: false, datagram: false, want: &LinuxPinger{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectPinger(tt.admin, tt.datagram); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectPinger(%v, %v) = %#v, want %#v", tt.admin, tt.datagram, got, tt.want)
+			}
+		})
+	}
+}
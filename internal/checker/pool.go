@@ -0,0 +1,72 @@
+package checker
+
+import (
+	"sync"
+)
+
+// WorkerPool 固定数量的 worker 从队列中依次取出任务执行，
+// 用于限制同时进行的检测数量（外部 ping 进程、套接字等）
+type WorkerPool struct {
+	tasks chan func()
+	wg    sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewWorkerPool 创建 workers 个 worker，队列最多容纳 queueSize 个等待中的任务
+func NewWorkerPool(workers, queueSize int) *WorkerPool {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	p := &WorkerPool{tasks: make(chan func(), queueSize)}
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer p.wg.Done()
+			for task := range p.tasks {
+				task()
+			}
+		}()
+	}
+	return p
+}
+
+// Submit 将任务加入队列，队列已满或已停止时返回 false，不会阻塞调用方
+func (p *WorkerPool) Submit(task func()) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return false
+	}
+
+	select {
+	case p.tasks <- task:
+		return true
+	default:
+		return false
+	}
+}
+
+// QueueLength 返回队列中等待执行的任务数
+func (p *WorkerPool) QueueLength() int {
+	return len(p.tasks)
+}
+
+// Stop 停止接收新任务，等待队列中的任务执行完毕
+func (p *WorkerPool) Stop() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.tasks)
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
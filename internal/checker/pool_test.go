@@ -0,0 +1,70 @@
+package checker
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWorkerPoolLimitsConcurrency(t *testing.T) {
+	pool := NewWorkerPool(2, 10)
+
+	var running, maxRunning atomic.Int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		ok := pool.Submit(func() {
+			defer wg.Done()
+			n := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			<-release
+			running.Add(-1)
+		})
+		if !ok {
+			t.Fatalf("submit %d rejected", i)
+		}
+	}
+
+	close(release)
+	wg.Wait()
+	pool.Stop()
+
+	if got := maxRunning.Load(); got > 2 {
+		t.Errorf("max concurrent tasks = %d, want <= 2", got)
+	}
+}
+
+func TestWorkerPoolQueueFull(t *testing.T) {
+	pool := NewWorkerPool(1, 1)
+	defer pool.Stop()
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	pool.Submit(func() {
+		close(started)
+		<-block
+	})
+	<-started
+
+	if !pool.Submit(func() {}) {
+		t.Fatal("task should be queued while the worker is busy")
+	}
+	if pool.Submit(func() {}) {
+		t.Error("submit should fail when the queue is full")
+	}
+	close(block)
+}
+
+func TestWorkerPoolStop(t *testing.T) {
+	pool := NewWorkerPool(1, 1)
+	pool.Stop()
+	if pool.Submit(func() {}) {
+		t.Error("submit should fail after stop")
+	}
+}
@@ -0,0 +1,34 @@
+package checker
+
+import (
+	"easy-check/internal/config"
+	"easy-check/internal/db"
+)
+
+// Prober 定义了 ping 以外的检测方式（如 tcp、http、tls、dns）
+type Prober interface {
+	// Probe 对主机执行 count 次探测，timeout 为单次探测超时（秒）
+	Probe(host config.Host, count int, timeout int) *ProbeResult
+}
+
+// ProbeResult 一次检测的结果，ping 与其他检测方式共用
+type ProbeResult struct {
+	Count        int                // 探测次数
+	SuccessCount int                // 成功次数
+	Latencies    []float64          // 每个成功探测的往返时间（毫秒）
+	Metrics      map[string]float64 // 检测方式特有的指标，与延迟指标一并写入 TSDB
+	Output       string             // 检测输出，失败时写入日志
+	Err          error              // 检测过程出错
+	Reason       db.ReasonType      // 失败原因类型，未设置时视为不可达
+	IPVersion    string             // 实际使用的地址族，写入 TSDB 的 ip_version 标签
+}
+
+// NewProbers 返回所有内置的检测器，key 为 config.Host 的 type
+func NewProbers() map[string]Prober {
+	return map[string]Prober{
+		config.HostTypeTCP:  &TCPProber{},
+		config.HostTypeHTTP: &HTTPProber{},
+		config.HostTypeTLS:  &TLSProber{},
+		config.HostTypeDNS:  &DNSProber{},
+	}
+}
@@ -0,0 +1,250 @@
+package checker
+
+import (
+	"easy-check/internal/config"
+	"easy-check/internal/db"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// 支持的 DNS 记录类型
+var dnsRecordTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+	"TXT":   dnsmessage.TypeTXT,
+	"MX":    dnsmessage.TypeMX,
+}
+
+// DNSProber 向指定的解析服务器查询记录，并与期望的结果比较
+type DNSProber struct{}
+
+func (p *DNSProber) Probe(host config.Host, count int, timeout int) *ProbeResult {
+	// 每次检测只发起一次查询
+	result := &ProbeResult{Count: 1, Reason: db.ReasonDNSFailure}
+	if host.Resolver == "" {
+		result.Err = fmt.Errorf("missing resolver for dns check")
+		return result
+	}
+
+	recordType := strings.ToUpper(host.RecordType)
+	if recordType == "" {
+		recordType = "A"
+	}
+	qtype, ok := dnsRecordTypes[recordType]
+	if !ok {
+		result.Err = fmt.Errorf("unsupported dns record type: %s", host.RecordType)
+		return result
+	}
+
+	resolver := host.Resolver
+	if _, _, err := net.SplitHostPort(resolver); err != nil {
+		resolver = net.JoinHostPort(resolver, "53")
+	}
+
+	start := time.Now()
+	response, err := queryDNS(resolver, host.Host, qtype, time.Duration(timeout)*time.Second)
+	if err != nil {
+		result.Err = fmt.Errorf("dns query to %s failed: %v", resolver, err)
+		return result
+	}
+	latency := durationMs(start, time.Now())
+	result.Latencies = []float64{latency}
+	result.Metrics = map[string]float64{
+		"dns_latency": latency,
+	}
+
+	if response.RCode != dnsmessage.RCodeSuccess {
+		result.Err = fmt.Errorf("dns query for %s %s returned %s", host.Host, recordType, dnsRCodeName(response.RCode))
+		return result
+	}
+
+	answers := extractDNSAnswers(response, qtype)
+	result.Output = fmt.Sprintf("%s %s @%s: %s time=%.2fms\n", host.Host, recordType, resolver, strings.Join(answers, ", "), latency)
+	result.SuccessCount = 1
+
+	if len(answers) == 0 {
+		result.Err = fmt.Errorf("dns query for %s %s returned no answer", host.Host, recordType)
+		return result
+	}
+	if len(host.Expected) > 0 && !sameDNSAnswers(answers, host.Expected) {
+		result.Reason = db.ReasonDNSMismatch
+		result.Err = fmt.Errorf("dns answer mismatch: got [%s], expected [%s]", strings.Join(answers, ", "), strings.Join(host.Expected, ", "))
+		return result
+	}
+
+	return result
+}
+
+// queryDNS 通过 UDP 查询，响应被截断时改用 TCP 重试
+func queryDNS(resolver, name string, qtype dnsmessage.Type, timeout time.Duration) (*dnsmessage.Message, error) {
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	qname, err := dnsmessage.NewName(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name %q: %v", name, err)
+	}
+
+	id := uint16(rand.Intn(1 << 16))
+	query := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: id, RecursionDesired: true},
+		Questions: []dnsmessage.Question{
+			{Name: qname, Type: qtype, Class: dnsmessage.ClassINET},
+		},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack query: %v", err)
+	}
+
+	response, err := exchangeDNS("udp", resolver, packed, id, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if response.Truncated {
+		response, err = exchangeDNS("tcp", resolver, packed, id, timeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// exchangeDNS 发送查询报文并解析响应，TCP 报文带 2 字节长度前缀
+// UDP 下忽略无法解析或 ID 不匹配的报文（如上一次查询迟到的响应），继续等待直到超时
+func exchangeDNS(network, resolver string, packed []byte, id uint16, timeout time.Duration) (*dnsmessage.Message, error) {
+	conn, err := net.DialTimeout(network, resolver, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if network != "tcp" {
+		if _, err := conn.Write(packed); err != nil {
+			return nil, err
+		}
+		reply := make([]byte, 4096)
+		for {
+			n, err := conn.Read(reply)
+			if err != nil {
+				return nil, err
+			}
+			var response dnsmessage.Message
+			if err := response.Unpack(reply[:n]); err != nil || response.ID != id {
+				continue
+			}
+			return &response, nil
+		}
+	}
+
+	frame := make([]byte, 2+len(packed))
+	binary.BigEndian.PutUint16(frame, uint16(len(packed)))
+	copy(frame[2:], packed)
+	if _, err := conn.Write(frame); err != nil {
+		return nil, err
+	}
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+	reply := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return nil, err
+	}
+
+	var response dnsmessage.Message
+	if err := response.Unpack(reply); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+	if response.ID != id {
+		return nil, fmt.Errorf("response id %d does not match query id %d", response.ID, id)
+	}
+	return &response, nil
+}
+
+// extractDNSAnswers 提取响应中与查询类型一致的记录，转换为可比较的字符串
+func extractDNSAnswers(response *dnsmessage.Message, qtype dnsmessage.Type) []string {
+	var answers []string
+	for _, answer := range response.Answers {
+		if answer.Header.Type != qtype {
+			continue
+		}
+		switch body := answer.Body.(type) {
+		case *dnsmessage.AResource:
+			answers = append(answers, net.IP(body.A[:]).String())
+		case *dnsmessage.AAAAResource:
+			answers = append(answers, net.IP(body.AAAA[:]).String())
+		case *dnsmessage.CNAMEResource:
+			answers = append(answers, strings.TrimSuffix(body.CNAME.String(), "."))
+		case *dnsmessage.MXResource:
+			answers = append(answers, strings.TrimSuffix(body.MX.String(), "."))
+		case *dnsmessage.TXTResource:
+			answers = append(answers, strings.Join(body.TXT, ""))
+		}
+	}
+	return answers
+}
+
+// sameDNSAnswers 比较实际结果与期望结果是否为同一集合（忽略顺序、大小写和末尾的点）
+func sameDNSAnswers(answers, expected []string) bool {
+	normalize := func(values []string) []string {
+		set := make(map[string]struct{}, len(values))
+		for _, v := range values {
+			v = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(v)), ".")
+			if ip := net.ParseIP(v); ip != nil {
+				v = ip.String()
+			}
+			set[v] = struct{}{}
+		}
+		result := make([]string, 0, len(set))
+		for v := range set {
+			result = append(result, v)
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	a, e := normalize(answers), normalize(expected)
+	if len(a) != len(e) {
+		return false
+	}
+	for i := range a {
+		if a[i] != e[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// dnsRCodeNames 响应码的标准助记名（RFC 1035、RFC 2136）
+var dnsRCodeNames = map[dnsmessage.RCode]string{
+	dnsmessage.RCodeSuccess:        "NOERROR",
+	dnsmessage.RCodeFormatError:    "FORMERR",
+	dnsmessage.RCodeServerFailure:  "SERVFAIL",
+	dnsmessage.RCodeNameError:      "NXDOMAIN",
+	dnsmessage.RCodeNotImplemented: "NOTIMP",
+	dnsmessage.RCodeRefused:        "REFUSED",
+	6:                              "YXDOMAIN",
+	7:                              "YXRRSET",
+	8:                              "NXRRSET",
+	9:                              "NOTAUTH",
+	10:                             "NOTZONE",
+}
+
+// dnsRCodeName 返回响应码名称，如 NXDOMAIN、SERVFAIL，未知的响应码返回 RCODE<n>
+func dnsRCodeName(rcode dnsmessage.RCode) string {
+	if name, ok := dnsRCodeNames[rcode]; ok {
+		return name
+	}
+	return fmt.Sprintf("RCODE%d", rcode)
+}
@@ -0,0 +1,133 @@
+package checker
+
+import (
+	"easy-check/internal/config"
+	"easy-check/internal/db"
+	"net"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// startDNSStub 启动一个本地 UDP DNS 服务，按域名返回固定的 A 记录
+// 每次响应前先发送一个 ID 不匹配的报文，模拟迟到的响应
+func startDNSStub(t *testing.T, records map[string][4]byte) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) == 0 {
+				continue
+			}
+			q := query.Questions[0]
+			response := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, RecursionAvailable: true},
+				Questions: query.Questions,
+			}
+			if ip, ok := records[q.Name.String()]; ok {
+				response.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+					Body:   &dnsmessage.AResource{A: ip},
+				}}
+			} else {
+				response.RCode = dnsmessage.RCodeNameError
+			}
+			stray := response
+			stray.ID++
+			if packed, err := stray.Pack(); err == nil {
+				conn.WriteTo(packed, addr)
+			}
+			packed, err := response.Pack()
+			if err != nil {
+				continue
+			}
+			conn.WriteTo(packed, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestDNSProber(t *testing.T) {
+	resolver := startDNSStub(t, map[string][4]byte{
+		"example.test.": {10, 0, 0, 1},
+	})
+
+	tests := []struct {
+		name       string
+		host       config.Host
+		wantErr    bool
+		wantReason db.ReasonType
+		wantErrMsg string
+	}{
+		{
+			name: "answer matches",
+			host: config.Host{Host: "example.test", Resolver: resolver, Expected: []string{"10.0.0.1"}},
+		},
+		{
+			name: "no expected answers",
+			host: config.Host{Host: "example.test", Resolver: resolver, RecordType: "a"},
+		},
+		{
+			name:       "answer mismatch",
+			host:       config.Host{Host: "example.test", Resolver: resolver, Expected: []string{"10.0.0.2"}},
+			wantErr:    true,
+			wantReason: db.ReasonDNSMismatch,
+		},
+		{
+			name:       "nxdomain",
+			host:       config.Host{Host: "missing.test", Resolver: resolver},
+			wantErr:    true,
+			wantReason: db.ReasonDNSFailure,
+			wantErrMsg: "NXDOMAIN",
+		},
+		{
+			name:       "unsupported record type",
+			host:       config.Host{Host: "example.test", Resolver: resolver, RecordType: "SRV"},
+			wantErr:    true,
+			wantReason: db.ReasonDNSFailure,
+		},
+	}
+
+	prober := &DNSProber{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.host.Type = config.HostTypeDNS
+			result := prober.Probe(tt.host, 1, 2)
+			if (result.Err != nil) != tt.wantErr {
+				t.Fatalf("Probe() err = %v, wantErr %v", result.Err, tt.wantErr)
+			}
+			if tt.wantErr && result.Reason != tt.wantReason {
+				t.Errorf("Reason = %s, want %s", result.Reason, tt.wantReason)
+			}
+			if tt.wantErrMsg != "" && !strings.Contains(result.Err.Error(), tt.wantErrMsg) {
+				t.Errorf("Err = %v, want it to contain %s", result.Err, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestDNSProberTimeout(t *testing.T) {
+	// 只监听不响应，模拟解析服务器超时
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer conn.Close()
+
+	result := (&DNSProber{}).Probe(config.Host{Host: "example.test", Type: config.HostTypeDNS, Resolver: conn.LocalAddr().String()}, 1, 1)
+	if result.Err == nil || result.Reason != db.ReasonDNSFailure {
+		t.Fatalf("expected dns failure on timeout, got err=%v reason=%s", result.Err, result.Reason)
+	}
+}
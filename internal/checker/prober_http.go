@@ -0,0 +1,136 @@
+package checker
+
+import (
+	"crypto/tls"
+	"easy-check/internal/config"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// 响应体最多读取的字节数，避免大文件占用内存
+const maxHTTPBodySize = 1 << 20
+
+// HTTPProber 请求 http/https 地址，校验状态码和响应体，并记录各阶段耗时
+type HTTPProber struct{}
+
+func (p *HTTPProber) Probe(host config.Host, count int, timeout int) *ProbeResult {
+	// 每次检测只发起一次请求
+	result := &ProbeResult{Count: 1}
+	if host.URL == "" {
+		result.Err = fmt.Errorf("missing url for http check")
+		return result
+	}
+
+	method := host.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequest(strings.ToUpper(method), host.URL, nil)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to create http request: %v", err)
+		return result
+	}
+	for key, value := range host.Headers {
+		if strings.EqualFold(key, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(key, value)
+	}
+
+	// 通过 httptrace 记录 DNS、连接、TLS 握手和首字节时间
+	var dnsStart, dnsDone, connectStart, connectDone, tlsStart, tlsDone, firstByte time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { dnsDone = time.Now() },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { connectDone = time.Now() },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { tlsDone = time.Now() },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	// 每次都新建连接，保证各阶段耗时完整
+	client := &http.Client{
+		Timeout: time.Duration(timeout) * time.Second,
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			DisableKeepAlives: true,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: host.SkipVerify},
+		},
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Err = fmt.Errorf("http request failed: %v", err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBodySize))
+	total := durationMs(start, time.Now())
+	result.Latencies = []float64{total}
+	result.Metrics = map[string]float64{
+		"dns_latency":     durationMs(dnsStart, dnsDone),
+		"connect_latency": durationMs(connectStart, connectDone),
+		"tls_latency":     durationMs(tlsStart, tlsDone),
+		"ttfb_latency":    durationMs(start, firstByte),
+		"status_code":     float64(resp.StatusCode),
+	}
+	result.Output = fmt.Sprintf("%s %s: status=%d time=%.2fms\n", req.Method, host.URL, resp.StatusCode, total)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to read response body: %v", err)
+		return result
+	}
+
+	if !isExpectedStatus(resp.StatusCode, host.ExpectedStatus) {
+		result.Err = fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		return result
+	}
+	if host.BodyMatch != "" && !strings.Contains(string(body), host.BodyMatch) {
+		result.Err = fmt.Errorf("response body does not contain %q", host.BodyMatch)
+		return result
+	}
+	if host.BodyRegex != "" {
+		re, err := regexp.Compile(host.BodyRegex)
+		if err != nil {
+			result.Err = fmt.Errorf("invalid body_regex: %v", err)
+			return result
+		}
+		if !re.Match(body) {
+			result.Err = fmt.Errorf("response body does not match %q", host.BodyRegex)
+			return result
+		}
+	}
+
+	result.SuccessCount = 1
+	return result
+}
+
+// isExpectedStatus 判断状态码是否符合预期，未配置时 2xx/3xx 视为成功
+func isExpectedStatus(code int, expected []int) bool {
+	if len(expected) == 0 {
+		return code >= 200 && code < 400
+	}
+	for _, e := range expected {
+		if code == e {
+			return true
+		}
+	}
+	return false
+}
+
+// durationMs 计算两个时间点之间的毫秒数，任一时间点缺失时返回 0
+func durationMs(start, end time.Time) float64 {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return float64(end.Sub(start).Microseconds()) / 1000
+}
@@ -0,0 +1,103 @@
+package checker
+
+import (
+	"easy-check/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPProber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			if r.Header.Get("X-Token") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"status":"ok","version":"1.2.3"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		host    config.Host
+		wantErr bool
+	}{
+		{
+			name: "status and body match",
+			host: config.Host{
+				URL:       server.URL + "/health",
+				Headers:   map[string]string{"X-Token": "secret"},
+				BodyMatch: `"status":"ok"`,
+				BodyRegex: `"version":"\d+\.\d+\.\d+"`,
+			},
+		},
+		{
+			name:    "missing header",
+			host:    config.Host{URL: server.URL + "/health"},
+			wantErr: true,
+		},
+		{
+			name: "expected status",
+			host: config.Host{URL: server.URL + "/missing", ExpectedStatus: []int{404}},
+		},
+		{
+			name: "body mismatch",
+			host: config.Host{
+				URL:       server.URL + "/health",
+				Headers:   map[string]string{"X-Token": "secret"},
+				BodyMatch: "degraded",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "missing url",
+			host:    config.Host{},
+			wantErr: true,
+		},
+	}
+
+	prober := &HTTPProber{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.host.Type = config.HostTypeHTTP
+			result := prober.Probe(tt.host, 1, 2)
+			if (result.Err != nil) != tt.wantErr {
+				t.Fatalf("Probe() err = %v, wantErr %v", result.Err, tt.wantErr)
+			}
+			if !tt.wantErr && result.SuccessCount != 1 {
+				t.Errorf("SuccessCount = %d, want 1", result.SuccessCount)
+			}
+			if tt.host.URL != "" {
+				if _, ok := result.Metrics["ttfb_latency"]; !ok {
+					t.Error("missing ttfb_latency metric")
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPProberTLSTiming(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// 自签名证书默认校验失败
+	result := (&HTTPProber{}).Probe(config.Host{URL: server.URL, Type: config.HostTypeHTTP}, 1, 2)
+	if result.Err == nil {
+		t.Fatal("expected certificate verification error")
+	}
+
+	result = (&HTTPProber{}).Probe(config.Host{URL: server.URL, Type: config.HostTypeHTTP, SkipVerify: true}, 1, 2)
+	if result.Err != nil {
+		t.Fatalf("Probe() err = %v", result.Err)
+	}
+	if result.Metrics["tls_latency"] <= 0 {
+		t.Errorf("tls_latency = %f, want > 0", result.Metrics["tls_latency"])
+	}
+}
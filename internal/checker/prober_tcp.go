@@ -0,0 +1,43 @@
+package checker
+
+import (
+	"bytes"
+	"easy-check/internal/config"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// TCPProber 通过建立 TCP 连接检测端口是否可达，适用于屏蔽 ICMP 的主机
+type TCPProber struct{}
+
+func (p *TCPProber) Probe(host config.Host, count int, timeout int) *ProbeResult {
+	result := &ProbeResult{Count: count}
+	if host.Port <= 0 || host.Port > 65535 {
+		result.Err = fmt.Errorf("invalid tcp port: %d", host.Port)
+		return result
+	}
+
+	address := net.JoinHostPort(host.Host, strconv.Itoa(host.Port))
+	var buffer bytes.Buffer
+	var latencies []float64
+	for i := 0; i < count; i++ {
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", address, time.Duration(timeout)*time.Second)
+		if err != nil {
+			buffer.WriteString(fmt.Sprintf("Connect to %s failed: seq=%d %v\n", address, i+1, err))
+			continue
+		}
+		latency := float64(time.Since(start).Microseconds()) / 1000
+		conn.Close()
+
+		latencies = append(latencies, latency)
+		buffer.WriteString(fmt.Sprintf("Connected to %s: seq=%d time=%.2fms\n", address, i+1, latency))
+	}
+
+	result.SuccessCount = len(latencies)
+	result.Latencies = latencies
+	result.Output = buffer.String()
+	return result
+}
@@ -0,0 +1,49 @@
+package checker
+
+import (
+	"easy-check/internal/config"
+	"net"
+	"testing"
+)
+
+func TestTCPProber(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	prober := &TCPProber{}
+	result := prober.Probe(config.Host{Host: "127.0.0.1", Type: config.HostTypeTCP, Port: port}, 3, 1)
+	if result.Err != nil {
+		t.Fatalf("Probe returned error: %v", result.Err)
+	}
+	if result.SuccessCount != 3 {
+		t.Errorf("SuccessCount = %d, want 3\n%s", result.SuccessCount, result.Output)
+	}
+	if len(result.Latencies) != 3 {
+		t.Errorf("got %d latencies, want 3", len(result.Latencies))
+	}
+
+	// 关闭监听后端口不可达
+	listener.Close()
+	result = prober.Probe(config.Host{Host: "127.0.0.1", Type: config.HostTypeTCP, Port: port}, 2, 1)
+	if result.SuccessCount != 0 {
+		t.Errorf("SuccessCount = %d after listener closed, want 0", result.SuccessCount)
+	}
+
+	result = prober.Probe(config.Host{Host: "127.0.0.1", Type: config.HostTypeTCP}, 1, 1)
+	if result.Err == nil {
+		t.Error("expected error for missing port")
+	}
+}
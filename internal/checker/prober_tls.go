@@ -0,0 +1,88 @@
+package checker
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"easy-check/internal/config"
+	"easy-check/internal/db"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"time"
+)
+
+// TLSProber 建立 TLS 连接并检查证书剩余有效天数
+type TLSProber struct{}
+
+func (p *TLSProber) Probe(host config.Host, count int, timeout int) *ProbeResult {
+	// 每次检测只建立一次连接
+	result := &ProbeResult{Count: 1}
+
+	port := host.Port
+	if port == 0 {
+		port = 443
+	}
+	address := net.JoinHostPort(host.Host, strconv.Itoa(port))
+
+	// 先跳过校验完成握手，保证过期证书也能读取到有效期，再单独校验证书链
+	dialer := &net.Dialer{Timeout: time.Duration(timeout) * time.Second}
+	start := time.Now()
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+		ServerName:         host.Host,
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		result.Err = fmt.Errorf("tls handshake failed: %v", err)
+		return result
+	}
+	defer conn.Close()
+	latency := durationMs(start, time.Now())
+	result.Latencies = []float64{latency}
+	result.SuccessCount = 1
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		result.Err = fmt.Errorf("no peer certificate presented by %s", address)
+		return result
+	}
+	leaf := certs[0]
+	daysRemaining := math.Floor(time.Until(leaf.NotAfter).Hours()/24*100) / 100
+	result.Metrics = map[string]float64{
+		"cert_days_remaining": daysRemaining,
+	}
+	result.Output = fmt.Sprintf("Certificate of %s: subject=%s issuer=%s not_after=%s days_remaining=%.2f\n",
+		address, leaf.Subject.CommonName, leaf.Issuer.CommonName, leaf.NotAfter.Format(time.RFC3339), daysRemaining)
+
+	if daysRemaining < float64(host.CertExpiryDays) {
+		result.Reason = db.ReasonCertExpiring
+		if daysRemaining < 0 {
+			result.Err = fmt.Errorf("certificate expired at %s", leaf.NotAfter.Format(time.RFC3339))
+		} else {
+			result.Err = fmt.Errorf("certificate expires in %.0f days (threshold %d)", math.Floor(daysRemaining), host.CertExpiryDays)
+		}
+		return result
+	}
+
+	if !host.SkipVerify {
+		if err := verifyCertificates(certs, host.Host); err != nil {
+			result.Err = fmt.Errorf("certificate verification failed: %v", err)
+			return result
+		}
+	}
+
+	return result
+}
+
+// verifyCertificates 使用系统根证书校验证书链和主机名
+func verifyCertificates(certs []*x509.Certificate, serverName string) error {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Intermediates: intermediates,
+	})
+	return err
+}
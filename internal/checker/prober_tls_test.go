@@ -0,0 +1,43 @@
+package checker
+
+import (
+	"easy-check/internal/config"
+	"easy-check/internal/db"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestTLSProber(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	_, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	host := config.Host{Host: "127.0.0.1", Type: config.HostTypeTLS, Port: port, CertExpiryDays: 14}
+	prober := &TLSProber{}
+
+	// httptest 使用自签名证书，默认校验失败但不属于证书过期
+	result := prober.Probe(host, 1, 2)
+	if result.Err == nil || result.Reason == db.ReasonCertExpiring {
+		t.Fatalf("expected verification error, got err=%v reason=%s", result.Err, result.Reason)
+	}
+	if result.Metrics["cert_days_remaining"] <= 14 {
+		t.Errorf("cert_days_remaining = %f, want > 14", result.Metrics["cert_days_remaining"])
+	}
+
+	host.SkipVerify = true
+	result = prober.Probe(host, 1, 2)
+	if result.Err != nil {
+		t.Fatalf("Probe() err = %v", result.Err)
+	}
+
+	// 阈值大于剩余天数时应以证书过期原因告警
+	host.CertExpiryDays = 1000000
+	result = prober.Probe(host, 1, 2)
+	if result.Err == nil || result.Reason != db.ReasonCertExpiring {
+		t.Fatalf("expected cert expiring alert, got err=%v reason=%s", result.Err, result.Reason)
+	}
+}
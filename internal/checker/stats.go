@@ -0,0 +1,63 @@
+package checker
+
+import (
+	"math"
+	"sort"
+)
+
+// LatencyStats 由每个包的往返时间计算出的延迟统计（毫秒）
+type LatencyStats struct {
+	Min    float64
+	Avg    float64
+	Max    float64
+	StdDev float64 // 标准差
+	Jitter float64 // 抖动，即各包延迟与平均值的平均偏差
+	P50    float64
+	P95    float64
+	P99    float64
+}
+
+// calculateLatencyStats 计算延迟统计，没有延迟数据时各项均为 0
+func calculateLatencyStats(latencies []float64) LatencyStats {
+	var stats LatencyStats
+	if len(latencies) == 0 {
+		return stats
+	}
+
+	sorted := make([]float64, len(latencies))
+	copy(sorted, latencies)
+	sort.Float64s(sorted)
+
+	var total float64
+	for _, latency := range sorted {
+		total += latency
+	}
+	stats.Min = sorted[0]
+	stats.Max = sorted[len(sorted)-1]
+	stats.Avg = total / float64(len(sorted))
+
+	var absDeviation, squaredDeviation float64
+	for _, latency := range sorted {
+		deviation := latency - stats.Avg
+		absDeviation += math.Abs(deviation)
+		squaredDeviation += deviation * deviation
+	}
+	stats.Jitter = absDeviation / float64(len(sorted))
+	stats.StdDev = math.Sqrt(squaredDeviation / float64(len(sorted)))
+
+	stats.P50 = percentile(sorted, 50)
+	stats.P95 = percentile(sorted, 95)
+	stats.P99 = percentile(sorted, 99)
+	return stats
+}
+
+// percentile 在已排序的数据上按线性插值计算百分位数
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
@@ -0,0 +1,55 @@
+package checker
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateLatencyStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		latencies []float64
+		want      LatencyStats
+	}{
+		{
+			name:      "empty",
+			latencies: nil,
+			want:      LatencyStats{},
+		},
+		{
+			name:      "single",
+			latencies: []float64{12},
+			want:      LatencyStats{Min: 12, Avg: 12, Max: 12, P50: 12, P95: 12, P99: 12},
+		},
+		{
+			name:      "unsorted",
+			latencies: []float64{40, 10, 30, 20},
+			want: LatencyStats{
+				Min: 10, Avg: 25, Max: 40,
+				StdDev: math.Sqrt(125), Jitter: 10,
+				P50: 25, P95: 38.5, P99: 39.7,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateLatencyStats(tt.latencies)
+			fields := map[string][2]float64{
+				"Min":    {got.Min, tt.want.Min},
+				"Avg":    {got.Avg, tt.want.Avg},
+				"Max":    {got.Max, tt.want.Max},
+				"StdDev": {got.StdDev, tt.want.StdDev},
+				"Jitter": {got.Jitter, tt.want.Jitter},
+				"P50":    {got.P50, tt.want.P50},
+				"P95":    {got.P95, tt.want.P95},
+				"P99":    {got.P99, tt.want.P99},
+			}
+			for name, v := range fields {
+				if math.Abs(v[0]-v[1]) > 1e-9 {
+					t.Errorf("%s = %f, want %f", name, v[0], v[1])
+				}
+			}
+		})
+	}
+}
@@ -3,24 +3,170 @@ package config
 import (
 	"easy-check/internal/logger"
 	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 )
 
+// 主机检测类型
+const (
+	HostTypePing = "ping"
+	HostTypeTCP  = "tcp"
+	HostTypeHTTP = "http"
+	HostTypeTLS  = "tls"
+	HostTypeDNS  = "dns"
+)
+
+// 检测使用的 IP 地址族
+const (
+	IPVersion4    = "4"
+	IPVersion6    = "6"
+	IPVersionAuto = "auto" // 优先 IPv4，主机只有 IPv6 地址时使用 IPv6
+)
+
+// 延迟阈值比较的指标
+const (
+	LatencyMetricAvg = "avg"
+	LatencyMetricP95 = "p95"
+)
+
 // Host 主机配置
 type Host struct {
-	Host        string `yaml:"host"`
-	Description string `yaml:"description"`
-	FailAlert   *bool  `yaml:"fail_alert"`
+	Host        string   `yaml:"host"`
+	Description string   `yaml:"description"`
+	FailAlert   *bool    `yaml:"fail_alert"`
+	Type        string   `yaml:"type"`       // 检测类型：ping（默认）、tcp、http、tls、dns
+	Port        int      `yaml:"port"`       // tcp/tls 检测的端口，tls 默认 443
+	IPVersion   string   `yaml:"ip_version"` // ping 使用的地址族：4、6、auto，未配置时使用 ping.ip_version
+	Tags        []string `yaml:"tags"`       // 主机标签，可用于静默规则匹配
+	DependsOn   []string `yaml:"depends_on"` // 依赖的上游检测（见 Key，ping 检测即主机地址），上游告警时本主机的告警被抑制
+	SLATarget   float64  `yaml:"sla_target"` // 可用性目标（百分比），未配置时使用 sla 中的配置
+
+	// 覆盖全局 ping 配置，未配置（0）时使用 ping 中的对应值
+	Count          int     `yaml:"count"`           // 探测次数
+	Timeout        int     `yaml:"timeout"`         // 单次探测超时（秒）
+	Interval       int     `yaml:"interval"`        // 检测间隔（秒）
+	LossRate       float64 `yaml:"loss_rate"`       // 失败率阈值，如 0.05 或 5 表示 5%
+	PacketInterval int     `yaml:"packet_interval"` // 同一主机两个包之间的间隔（毫秒）
+
+	// 延迟告警阈值（毫秒），未配置时使用 alert 中的全局配置
+	LatencyWarn   float64 `yaml:"latency_warn"`   // 超过该值标记为降级（DEGRADED）
+	LatencyCrit   float64 `yaml:"latency_crit"`   // 超过该值标记为告警（ALERT）
+	LatencyMetric string  `yaml:"latency_metric"` // 比较的延迟指标：avg（默认）、p95
+
+	// 状态转换阈值，未配置时使用 alert 中的全局配置
+	FailThreshold     int `yaml:"fail_threshold"`     // 连续失败多少次后告警
+	RecoveryThreshold int `yaml:"recovery_threshold"` // 连续成功多少次后恢复
+
+	// http 检测配置
+	URL            string            `yaml:"url"`             // 请求地址，支持 http/https
+	Method         string            `yaml:"method"`          // 请求方法，默认 GET
+	Headers        map[string]string `yaml:"headers"`         // 请求头
+	ExpectedStatus []int             `yaml:"expected_status"` // 期望的状态码，默认 2xx/3xx
+	BodyMatch      string            `yaml:"body_match"`      // 响应体需包含的子串
+	BodyRegex      string            `yaml:"body_regex"`      // 响应体需匹配的正则
+	SkipVerify     bool              `yaml:"skip_verify"`     // 跳过 https/tls 证书校验
+
+	// tls 检测配置
+	CertExpiryDays int `yaml:"cert_expiry_days"` // 证书剩余天数低于该值时告警，未配置时使用 alert.cert_expiry_days
+
+	// dns 检测配置，查询的域名为 host
+	Resolver   string   `yaml:"resolver"`    // 解析服务器地址，如 223.5.5.5 或 223.5.5.5:53
+	RecordType string   `yaml:"record_type"` // 记录类型：A（默认）、AAAA、CNAME、TXT、MX
+	Expected   []string `yaml:"expected"`    // 期望的解析结果集合，为空时只要求有结果
+}
+
+// GetType 返回主机的检测类型，未配置时为 ping
+func (h Host) GetType() string {
+	if h.Type == "" {
+		return HostTypePing
+	}
+	return h.Type
+}
+
+// Key 返回检测的唯一标识，用作调度、告警状态、故障记录和 TSDB 的 host 标签
+// ping 检测为主机地址本身；同一地址上的其他检测按类型、端口、路径区分，
+// 如 tcp://10.0.0.1:22、tls://example.com:443、https://example.com/health、dns://223.5.5.5/example.com/A
+func (h Host) Key() string {
+	switch h.GetType() {
+	case HostTypeTCP:
+		return "tcp://" + net.JoinHostPort(h.Host, strconv.Itoa(h.Port))
+	case HostTypeTLS:
+		port := h.Port
+		if port == 0 {
+			port = 443
+		}
+		return "tls://" + net.JoinHostPort(h.Host, strconv.Itoa(port))
+	case HostTypeHTTP:
+		if h.URL != "" {
+			return h.URL
+		}
+		return "http://" + h.Host
+	case HostTypeDNS:
+		recordType := strings.ToUpper(h.RecordType)
+		if recordType == "" {
+			recordType = "A"
+		}
+		return fmt.Sprintf("dns://%s/%s/%s", h.Resolver, h.Host, recordType)
+	}
+	return h.Host
 }
 
 // PingConfig Ping相关配置
 type PingConfig struct {
-	Count    int     `yaml:"count"`
-	Timeout  int     `yaml:"timeout"`
-	Interval int     `yaml:"interval"`
-	LossRate float64 `yaml:"loss_rate"`
+	Count          int     `yaml:"count"`
+	Timeout        int     `yaml:"timeout"`
+	Interval       int     `yaml:"interval"`
+	LossRate       float64 `yaml:"loss_rate"`
+	IPVersion      string  `yaml:"ip_version"`      // 地址族：4、6、auto（默认）
+	PacketInterval int     `yaml:"packet_interval"` // 同一主机两个包之间的间隔（毫秒），默认 1000
+}
+
+// EffectivePingConfig 返回主机实际生效的 ping 配置
+// 主机配置优先，其次为全局 ping 配置，均未配置时使用默认值
+func (c *Config) EffectivePingConfig(host Host) PingConfig {
+	p := c.Ping
+	if host.Count > 0 {
+		p.Count = host.Count
+	}
+	if host.Timeout > 0 {
+		p.Timeout = host.Timeout
+	}
+	if host.Interval > 0 {
+		p.Interval = host.Interval
+	}
+	if host.LossRate > 0 {
+		p.LossRate = host.LossRate
+	}
+	if host.PacketInterval > 0 {
+		p.PacketInterval = host.PacketInterval
+	}
+	if host.IPVersion != "" {
+		p.IPVersion = host.IPVersion
+	}
+
+	if p.Count <= 0 {
+		p.Count = 4 // 默认与 Windows ping 命令一致，次数为 0 时丢包率无法计算且 ping -c 0 不会退出
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = 2 // 默认单次探测超时 2 秒
+	}
+	if p.Interval <= 0 {
+		p.Interval = c.Interval // 未配置 ping.interval 时使用全局 interval
+	}
+	if p.LossRate <= 0 {
+		p.LossRate = 0.2 // 默认失败率超过 20% 触发告警
+	}
+	if p.PacketInterval <= 0 {
+		p.PacketInterval = 1000 // 默认与系统 ping 命令一致
+	}
+	if p.IPVersion == "" {
+		p.IPVersion = IPVersionAuto
+	}
+	return p
 }
 
 // LogConfig 日志配置
@@ -49,24 +195,64 @@ type NotifierConfig struct {
 	Options map[string]interface{} `yaml:",inline"` // 存储特定通知器的配置
 }
 
+// EscalationConfig 告警持续 After 分钟仍未恢复时，额外通知 Notifiers 中的通知器
+type EscalationConfig struct {
+	After     int      `yaml:"after"`
+	Notifiers []string `yaml:"notifiers"` // 通知器名称，对应 alert.notifiers 中的 name
+}
+
 // AlertConfig 告警配置
 type AlertConfig struct {
-	FailAlert                     bool             `yaml:"fail_alert"`
-	AggregateAlerts               bool             `yaml:"aggregate_alerts"`
-	AggregateWindow               int              `yaml:"aggregate_window"`
-	AggregateAlertLineTemplate    string           `yaml:"aggregate_alert_line_template"`
-	AggregateRecoveryLineTemplate string           `yaml:"aggregate_recovery_line_template"`
-	Notifiers                     []NotifierConfig `yaml:"notifiers"`
+	FailAlert                     bool               `yaml:"fail_alert"`
+	AggregateAlerts               bool               `yaml:"aggregate_alerts"`
+	AggregateWindow               int                `yaml:"aggregate_window"`
+	AggregateAlertLineTemplate    string             `yaml:"aggregate_alert_line_template"`
+	AggregateRecoveryLineTemplate string             `yaml:"aggregate_recovery_line_template"`
+	AggregateDegradedLineTemplate string             `yaml:"aggregate_degraded_line_template"`
+	CertExpiryDays                int                `yaml:"cert_expiry_days"`
+	LatencyWarn                   float64            `yaml:"latency_warn"`       // 延迟降级阈值（毫秒），0 表示不检查
+	LatencyCrit                   float64            `yaml:"latency_crit"`       // 延迟告警阈值（毫秒），0 表示不检查
+	LatencyMetric                 string             `yaml:"latency_metric"`     // 比较的延迟指标：avg（默认）、p95
+	FailThreshold                 int                `yaml:"fail_threshold"`     // 连续失败多少次后告警，默认 1
+	RecoveryThreshold             int                `yaml:"recovery_threshold"` // 连续成功多少次后恢复，默认 1
+	FlapWindow                    int                `yaml:"flap_window"`        // 抖动检测窗口（秒），默认 600
+	FlapThreshold                 int                `yaml:"flap_threshold"`     // 窗口内状态切换多少次视为抖动，0 表示不检测
+	RenotifyInterval              int                `yaml:"renotify_interval"`  // 告警未恢复时每隔多少分钟重复通知，0 表示不重复
+	Escalations                   []EscalationConfig `yaml:"escalations"`        // 按告警持续时间逐级通知更多通知器
+	Notifiers                     []NotifierConfig   `yaml:"notifiers"`
+}
+
+// SLAConfig 可用性目标与定期报告配置
+type SLAConfig struct {
+	Target float64          `yaml:"target"` // 默认可用性目标（百分比），如 99.9，0 表示不设目标
+	Groups []SLAGroupConfig `yaml:"groups"` // 按主机标签设置的可用性目标
+	Report SLAReportConfig  `yaml:"report"`
+}
+
+// SLAGroupConfig 带有 Tag 标签的主机使用的可用性目标
+type SLAGroupConfig struct {
+	Tag    string  `yaml:"tag"`
+	Target float64 `yaml:"target"`
+}
+
+// SLAReportConfig 定期可用性报告配置，报告通过已启用的通知器发送
+type SLAReportConfig struct {
+	Enable   bool   `yaml:"enable"`
+	Schedule string `yaml:"schedule"` // 发送时间（cron 表达式），默认 "0 9 * * 1" 即每周一 09:00
+	Period   string `yaml:"period"`   // 统计周期：day、week（默认）、month，统计上一个完整周期
 }
 
 // Config 应用总配置
 type Config struct {
-	Hosts    []Host      `yaml:"hosts"`
-	Interval int         `yaml:"interval"`
-	Ping     PingConfig  `yaml:"ping"`
-	Log      LogConfig   `yaml:"log"`
-	Db       DbConfig    `yaml:"db"`
-	Alert    AlertConfig `yaml:"alert"`
+	Hosts       []Host      `yaml:"hosts"`
+	Interval    int         `yaml:"interval"`
+	Concurrency int         `yaml:"concurrency"` // 同时进行的检测数量上限，默认 32
+	QueueSize   int         `yaml:"queue_size"`  // 等待检测的队列长度，队列已满时跳过本次检测，默认 1024
+	Ping        PingConfig  `yaml:"ping"`
+	Log         LogConfig   `yaml:"log"`
+	Db          DbConfig    `yaml:"db"`
+	Alert       AlertConfig `yaml:"alert"`
+	SLA         SLAConfig   `yaml:"sla"`
 }
 
 // LoadConfig 从文件加载配置
@@ -91,6 +277,43 @@ func LoadConfig(configPath string, logger *logger.Logger) (*Config, error) {
 	return &config, nil
 }
 
+// IsEscalationNotifier 通知器是否只用于告警升级，升级中引用的通知器不接收首次通知
+func (a *AlertConfig) IsEscalationNotifier(name string) bool {
+	for _, escalation := range a.Escalations {
+		for _, n := range escalation.Notifiers {
+			if n == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SLATarget 返回主机的可用性目标：主机配置优先，其次为第一个匹配标签的分组，最后为全局目标
+func (c *Config) SLATarget(host Host) float64 {
+	if host.SLATarget > 0 {
+		return host.SLATarget
+	}
+	for _, group := range c.SLA.Groups {
+		for _, tag := range host.Tags {
+			if tag == group.Tag {
+				return group.Target
+			}
+		}
+	}
+	return c.SLA.Target
+}
+
+// GroupSLATarget 返回标签分组的可用性目标，未单独配置时使用全局目标
+func (c *Config) GroupSLATarget(tag string) float64 {
+	for _, group := range c.SLA.Groups {
+		if group.Tag == tag {
+			return group.Target
+		}
+	}
+	return c.SLA.Target
+}
+
 // GetNotifierByType 根据类型获取指定通知器配置
 func (c *Config) GetNotifierByType(notifierType string) (*NotifierConfig, bool) {
 	for _, n := range c.Alert.Notifiers {
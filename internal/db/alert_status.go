@@ -6,6 +6,7 @@ import (
 	"easy-check/internal/logger"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/badger/v4"
@@ -15,8 +16,23 @@ type StatusType string
 type SentType bool
 
 const (
-	StatusAlert    StatusType = "ALERT"
-	StatusRecovery StatusType = "RECOVERY"
+	StatusAlert        StatusType = "ALERT"
+	StatusDegraded     StatusType = "DEGRADED" // 主机可达但延迟超过告警阈值
+	StatusRecovery     StatusType = "RECOVERY"
+	StatusFlapping     StatusType = "FLAPPING"     // 短时间内状态频繁切换，期间不再发送告警/恢复通知
+	StatusAcknowledged StatusType = "ACKNOWLEDGED" // 告警已被确认处理，不再重复通知和升级
+)
+
+// ReasonType 告警原因类型，供通知模板区分展示
+type ReasonType string
+
+const (
+	ReasonUnreachable  ReasonType = "unreachable"   // 主机或服务不可达
+	ReasonCertExpiring ReasonType = "cert_expiring" // 证书即将过期或已过期
+	ReasonDNSFailure   ReasonType = "dns_failure"   // 域名解析失败（NXDOMAIN、超时等）
+	ReasonDNSMismatch  ReasonType = "dns_mismatch"  // 解析结果与期望不一致
+	ReasonHighLatency  ReasonType = "high_latency"  // 延迟超过阈值
+	ReasonParentDown   ReasonType = "parent_down"   // 依赖的上游主机故障导致不可达
 )
 
 const (
@@ -29,16 +45,79 @@ type AlertStatusManager struct {
 	db       *badger.DB
 	logger   *logger.Logger
 	dbConfig *config.DbConfig
+
+	flapMu sync.RWMutex
+	flap   FlapConfig
+
+	// 检测器、通知消费者和界面操作会并发修改同一条记录，每条记录的读-改-写都需持有对应的锁
+	locksMu sync.Mutex
+	locks   map[string]*sync.Mutex
+}
+
+// FlapConfig 抖动检测配置：Window 内状态切换次数达到 Threshold 时进入抖动，
+// 降到 Threshold 的一半及以下时结束抖动；Threshold 为 0 时不检测
+type FlapConfig struct {
+	Window    time.Duration
+	Threshold int
 }
 
 type AlertStatus struct {
-	Host         string     `json:"host"`
+	Host         string     `json:"host"` // 检测标识（config.Host.Key），ping 检测即主机地址
 	Description  string     `json:"description"`
 	FailAlert    bool       `json:"fail_alert"`
 	Status       StatusType `json:"status"`
 	FailTime     string     `json:"fail_time"`
 	RecoveryTime string     `json:"recovery_time"`
 	Sent         bool       `json:"sent"`
+	Reason       ReasonType `json:"reason"` // 告警原因类型
+	Detail       string     `json:"detail"` // 告警原因详情
+
+	// 连续失败/成功次数，达到阈值时才转换状态，随记录持久化以便重启后延续
+	FailStreak    int `json:"fail_streak"`
+	SuccessStreak int `json:"success_streak"`
+
+	// 抖动检测
+	Transitions []string   `json:"transitions"`  // 最近的状态切换时间（RFC3339），只保留窗口内的记录
+	FlapState   StatusType `json:"flap_state"`   // 抖动期间主机实际所处的状态
+	FlapStopped bool       `json:"flap_stopped"` // 抖动刚结束，待发送抖动结束通知
+
+	// 重复通知与升级
+	LastNotifiedAt  string `json:"last_notified_at"` // 最近一次发送通知的时间（RFC3339）
+	EscalationLevel int    `json:"escalation_level"` // 已通知到的升级级别，0 表示未升级
+
+	// 告警确认
+	AckState   StatusType `json:"ack_state"` // 确认期间主机实际所处的状态
+	AckBy      string     `json:"ack_by"`
+	AckAt      string     `json:"ack_at"` // RFC3339
+	AckComment string     `json:"ack_comment"`
+
+	// 依赖抑制
+	SuppressedBy string   `json:"suppressed_by"` // 因该上游主机故障而被抑制通知
+	Dependents   []string `json:"-"`             // 仅用于通知：本次因本主机故障被抑制的下游主机
+
+	IncidentID  string `json:"incident_id"`           // 关联的故障记录
+	Unannounced bool   `json:"unannounced,omitempty"` // 本次故障尚未发送过通知（如被静默或抑制），恢复时不发送恢复通知
+}
+
+// State 返回主机实际所处的状态，抖动或确认期间为记录的实际状态
+func (s *AlertStatus) State() StatusType {
+	return currentState(*s)
+}
+
+// IsFlapNotice 是否为抖动开始/结束通知
+func (s *AlertStatus) IsFlapNotice() bool {
+	return s.Status == StatusFlapping || s.FlapStopped
+}
+
+// currentState 返回主机实际所处的状态，抖动或确认期间为记录的实际状态
+func currentState(s AlertStatus) StatusType {
+	switch s.Status {
+	case StatusFlapping:
+		return s.FlapState
+	case StatusAcknowledged:
+		return s.AckState
+	}
+	return s.Status
 }
 
 // NewAlertStatusManager 创建一个新的 AlertStatusManager
@@ -49,6 +128,112 @@ func NewAlertStatusManager(dbInstance *badger.DB, logger *logger.Logger, dbConfi
 	return &AlertStatusManager{db: dbInstance, logger: logger, dbConfig: &dbConfig}, nil
 }
 
+// SetFlapConfig 更新抖动检测配置
+func (d *AlertStatusManager) SetFlapConfig(flap FlapConfig) {
+	d.flapMu.Lock()
+	defer d.flapMu.Unlock()
+	d.flap = flap
+}
+
+func (d *AlertStatusManager) getFlapConfig() FlapConfig {
+	d.flapMu.RLock()
+	defer d.flapMu.RUnlock()
+	return d.flap
+}
+
+// lock 锁定主机对应的记录，返回解锁函数
+func (d *AlertStatusManager) lock(host string) func() {
+	d.locksMu.Lock()
+	if d.locks == nil {
+		d.locks = make(map[string]*sync.Mutex)
+	}
+	mu, ok := d.locks[host]
+	if !ok {
+		mu = &sync.Mutex{}
+		d.locks[host] = mu
+	}
+	d.locksMu.Unlock()
+
+	mu.Lock()
+	return mu.Unlock
+}
+
+// expiringSoon 记录剩余有效期不足一半时返回 true，
+// 状态未变化时不改写记录，但长时间告警的记录仍需在过期前刷新
+func (d *AlertStatusManager) expiringSoon(expiresAt uint64) bool {
+	if expiresAt == 0 || d.dbConfig.Expire <= 0 {
+		return false
+	}
+	return time.Until(time.Unix(int64(expiresAt), 0)) < time.Duration(d.dbConfig.Expire)*time.Second/2
+}
+
+// saveTransition 保存一次状态切换，并根据窗口内的切换次数判断是否进入抖动
+// 抖动期间只更新实际状态，不重新触发通知
+func (d *AlertStatusManager) saveTransition(prev AlertStatus, next AlertStatus) error {
+	flap := d.getFlapConfig()
+	now := time.Now()
+	next.Transitions = append(pruneTransitions(prev.Transitions, now, flap.Window), now.Format(time.RFC3339))
+	next.FlapStopped = false
+	d.trackIncident(prev, &next)
+	if isProblem(currentState(next)) {
+		// 新故障开始时尚未通知，ALERT 与 DEGRADED 之间切换时沿用原故障的通知情况
+		next.Unannounced = !isProblem(currentState(prev)) || prev.Unannounced
+	}
+
+	if prev.Status == StatusFlapping {
+		next.FlapState = next.Status
+		next.Status = StatusFlapping
+		next.Sent = prev.Sent // 抖动开始通知可能尚未发送
+		next.Reason = prev.Reason
+		next.Detail = prev.Detail
+		d.logger.Log(fmt.Sprintf("Host %s is flapping, suppressing %s notification", next.Host, next.FlapState), "debug")
+	} else if flap.Threshold > 0 && len(next.Transitions) >= flap.Threshold {
+		next.FlapState = next.Status
+		next.Status = StatusFlapping
+		next.Sent = false
+		next.Detail = fmt.Sprintf("flapping started: %d state changes within %v", len(next.Transitions), flap.Window)
+		d.logger.Log(fmt.Sprintf("Host %s started flapping", next.Host), "warn")
+	} else {
+		next.FlapState = ""
+	}
+
+	return d.SetAlertStatus(next, d.dbConfig.Expire)
+}
+
+// checkFlapStop 窗口内的切换次数降到阈值一半及以下时结束抖动，恢复为实际状态并待发送抖动结束通知
+// 返回记录是否被修改
+func (d *AlertStatusManager) checkFlapStop(status *AlertStatus) bool {
+	if status.Status != StatusFlapping {
+		return false
+	}
+	flap := d.getFlapConfig()
+	status.Transitions = pruneTransitions(status.Transitions, time.Now(), flap.Window)
+	if flap.Threshold > 0 && len(status.Transitions) > flap.Threshold/2 {
+		return true
+	}
+
+	status.Status = status.FlapState
+	status.FlapState = ""
+	status.FlapStopped = true
+	status.Sent = false
+	status.Detail = fmt.Sprintf("flapping stopped, current state %s", status.Status)
+	d.logger.Log(fmt.Sprintf("Host %s stopped flapping, current state %s", status.Host, status.Status), "info")
+	return true
+}
+
+// pruneTransitions 只保留窗口内的切换时间
+func pruneTransitions(transitions []string, now time.Time, window time.Duration) []string {
+	var kept []string
+	for _, t := range transitions {
+		ts, err := time.Parse(time.RFC3339, t)
+		if err != nil || now.Sub(ts) > window {
+			continue
+		}
+		kept = append(kept, t)
+	}
+	return kept
+}
+
 // SetAlertStatus 保存告警状态
 func (d *AlertStatusManager) SetAlertStatus(status AlertStatus, ttlSeconds int) error {
 	if d == nil {
@@ -79,13 +264,21 @@ func (d *AlertStatusManager) SetAlertStatus(status AlertStatus, ttlSeconds int)
 
 // GetAlertStatus 获取告警状态
 func (d *AlertStatusManager) GetAlertStatus(host string) (AlertStatus, error) {
+	status, _, err := d.getAlertStatus(host)
+	return status, err
+}
+
+// getAlertStatus 获取告警状态及记录的过期时间（Unix 秒，0 表示不过期）
+func (d *AlertStatusManager) getAlertStatus(host string) (AlertStatus, uint64, error) {
 	var status AlertStatus
+	var expiresAt uint64
 	key := GenerateAlertStatusKey(host)
 	err := d.db.View(func(txn *badger.Txn) error {
 		item, err := txn.Get(key)
 		if err != nil {
 			return err
 		}
+		expiresAt = item.ExpiresAt()
 		return item.Value(func(v []byte) error {
 			return json.Unmarshal(v, &status)
 		})
@@ -93,11 +286,11 @@ func (d *AlertStatusManager) GetAlertStatus(host string) (AlertStatus, error) {
 	if err != nil {
 		if err == badger.ErrKeyNotFound {
 			// 记录不存在是正常情况，返回特定错误便于上层函数识别
-			return AlertStatus{}, badger.ErrKeyNotFound
+			return AlertStatus{}, 0, badger.ErrKeyNotFound
 		}
-		return AlertStatus{}, fmt.Errorf("failed to get alert status for host %s: %w", host, err)
+		return AlertStatus{}, 0, fmt.Errorf("failed to get alert status for host %s: %w", host, err)
 	}
-	return status, nil
+	return status, expiresAt, nil
 }
 
 // DeleteAlertStatus 删除告警状态
@@ -108,44 +301,157 @@ func (d *AlertStatusManager) DeleteAlertStatus(host string) error {
 	})
 }
 
-// MarkAsAlert 将主机状态标记为 ALERT
-func (d *AlertStatusManager) MarkAsAlert(status AlertStatus) error {
-	// 获取数据库中现有的状态
+// transitionToAlert 将已有记录切换为 ALERT，调用方需持有记录的锁
+func (d *AlertStatusManager) transitionToAlert(existingStatus AlertStatus, status AlertStatus) error {
+	// 如果数据库中状态已经是 ALERT，则无需更新
+	current := currentState(existingStatus)
+	if current == StatusAlert {
+		d.logger.Log(fmt.Sprintf("Host %s is already in ALERT state, skipping update", status.Host), "debug")
+		return nil
+	}
+
+	// 从 DEGRADED 升级为 ALERT 时沿用降级开始的时间
+	if current == StatusDegraded && existingStatus.FailTime != "" {
+		status.FailTime = existingStatus.FailTime
+	}
+
+	// 如果数据库中状态是 RECOVERY 或 DEGRADED，则更新为传入的完整状态
+	d.logger.Log(fmt.Sprintf("Updating host %s from %s to ALERT", status.Host, current), "debug")
+	return d.saveTransition(existingStatus, status)
+}
+
+// transitionToDegraded 将已有记录切换为 DEGRADED，调用方需持有记录的锁
+// 从 ALERT 回落到 DEGRADED 时沿用原告警的开始时间，并重新发送降级通知
+func (d *AlertStatusManager) transitionToDegraded(existingStatus AlertStatus, status AlertStatus) error {
+	// 如果数据库中状态已经是 DEGRADED，则无需更新
+	current := currentState(existingStatus)
+	if current == StatusDegraded {
+		d.logger.Log(fmt.Sprintf("Host %s is already in DEGRADED state, skipping update", status.Host), "debug")
+		return nil
+	}
+
+	if current == StatusAlert && existingStatus.FailTime != "" {
+		status.FailTime = existingStatus.FailTime
+	}
+
+	d.logger.Log(fmt.Sprintf("Updating host %s from %s to DEGRADED", status.Host, current), "debug")
+	return d.saveTransition(existingStatus, status)
+}
+
+// RecordFailure 记录一次失败（或降级）检测，连续次数达到 threshold 时
+// 按 status.Status 转换为 ALERT 或 DEGRADED，否则只更新计数
+// 整个读-改-写持有记录的锁，不会覆盖通知消费者或确认操作同时写入的 Sent、确认等字段
+func (d *AlertStatusManager) RecordFailure(status AlertStatus, threshold int) error {
+	defer d.lock(status.Host)()
+
+	existingStatus, expiresAt, err := d.getAlertStatus(status.Host)
+	if err != nil && err != badger.ErrKeyNotFound {
+		return fmt.Errorf("failed to get alert status: %w", err)
+	}
+	found := err == nil
+	if !found {
+		// 尚无记录时从已发送的 RECOVERY 记录开始，首次告警无论阈值是否为 1 都会发送
+		existingStatus = AlertStatus{
+			Host:        status.Host,
+			Description: status.Description,
+			Status:      StatusRecovery,
+			Sent:        true,
+		}
+	}
+
+	failStreak := existingStatus.FailStreak + 1
+	unchanged := currentState(existingStatus) == status.Status
+	if unchanged {
+		// 已处于该状态时计数不再超过阈值，持续失败时无需每次改写记录
+		failStreak = min(failStreak, max(threshold, 1))
+	}
+
+	// 未达到阈值或状态不变时只更新计数
+	if failStreak < threshold || unchanged {
+		updated := existingStatus
+		updated.FailStreak = failStreak
+		updated.SuccessStreak = 0
+		flapChanged := d.checkFlapStop(&updated)
+		if found && !flapChanged && updated.FailStreak == existingStatus.FailStreak &&
+			updated.SuccessStreak == existingStatus.SuccessStreak && !d.expiringSoon(expiresAt) {
+			return nil
+		}
+		d.logger.Log(fmt.Sprintf("Host %s failed %d/%d consecutive checks", status.Host, failStreak, threshold), "debug")
+		return d.SetAlertStatus(updated, d.dbConfig.Expire)
+	}
+
+	status.FailStreak = failStreak
+	status.SuccessStreak = 0
+	if status.Status == StatusDegraded {
+		return d.transitionToDegraded(existingStatus, status)
+	}
+	return d.transitionToAlert(existingStatus, status)
+}
+
+// RecordSuccess 记录一次成功检测，处于 ALERT 或 DEGRADED 时连续成功达到 threshold 才恢复
+func (d *AlertStatusManager) RecordSuccess(status AlertStatus, threshold int) error {
+	defer d.lock(status.Host)()
+
 	existingStatus, err := d.GetAlertStatus(status.Host)
 	if err != nil {
-		// 如果是 Key 不存在的错误，直接插入新状态
 		if err == badger.ErrKeyNotFound {
-			d.logger.Log(fmt.Sprintf("Creating new alert status record for host: %s", status.Host), "debug")
-			
-			// 重要修复：对于新创建的告警记录，如果主机已经处于失败状态较长时间，
-			// 应该避免立即发送告警，而是先标记为已发送，然后在下一个检查周期再决定是否需要重新告警
-			// 这可以通过检查 FailTime 来判断
-			if status.FailTime != "" {
-				// 如果 FailTime 存在，说明这不是第一次失败，可能是 TTL 过期后重新创建的记录
-				// 为了避免重复告警，将 Sent 设置为 true
-				status.Sent = true
-				d.logger.Log(fmt.Sprintf("Setting Sent=true for recreated alert record to avoid duplicate alerts for host: %s", status.Host), "debug")
-			}
-			
-			return d.SetAlertStatus(status, d.dbConfig.Expire)
+			// 没有记录说明一直正常，无需处理
+			return nil
 		}
-		// 其他错误直接返回
 		return fmt.Errorf("failed to get alert status: %w", err)
 	}
 
-	// 如果数据库中状态已经是 ALERT，则无需更新
-	if existingStatus.Status == StatusAlert {
-		d.logger.Log(fmt.Sprintf("Host %s is already in ALERT state, skipping update", status.Host), "debug")
-		return nil
+	current := currentState(existingStatus)
+	if current != StatusAlert && current != StatusDegraded {
+		// 未告警时成功一次即清空失败计数
+		flapChanged := d.checkFlapStop(&existingStatus)
+		if existingStatus.FailStreak == 0 && !flapChanged {
+			return nil
+		}
+		existingStatus.FailStreak = 0
+		return d.SetAlertStatus(existingStatus, d.dbConfig.Expire)
 	}
 
-	// 如果数据库中状态是 RECOVERY，则更新为传入的完整状态
-	d.logger.Log(fmt.Sprintf("Updating host %s from RECOVERY to ALERT", status.Host), "debug")
-	return d.SetAlertStatus(status, d.dbConfig.Expire)
+	successStreak := existingStatus.SuccessStreak + 1
+	if successStreak >= threshold {
+		return d.transitionToRecovery(existingStatus, status)
+	}
+
+	existingStatus.SuccessStreak = successStreak
+	existingStatus.FailStreak = 0
+	d.checkFlapStop(&existingStatus)
+	d.logger.Log(fmt.Sprintf("Host %s succeeded %d/%d consecutive checks", status.Host, successStreak, threshold), "debug")
+	return d.SetAlertStatus(existingStatus, d.dbConfig.Expire)
 }
 
 // GetAllUnsentStatuses 获取所有未发送的状态，根据传入的 Status 筛选
 func (d *AlertStatusManager) GetAllUnsentStatuses(statusType StatusType) ([]*AlertStatus, error) {
+	return d.filterStatuses(func(status *AlertStatus) bool {
+		// 过滤条件：sent 为 false 且 status 为指定类型，抖动开始/结束通知统一按 FLAPPING 筛选
+		match := status.Status == statusType && !status.FlapStopped
+		if statusType == StatusFlapping {
+			match = status.IsFlapNotice()
+		}
+		return !status.Sent && match
+	})
+}
+
+// GetNotifiedStatuses 获取已发送通知且仍处于指定状态的记录，用于重复通知与升级
+func (d *AlertStatusManager) GetNotifiedStatuses(statusType StatusType) ([]*AlertStatus, error) {
+	return d.filterStatuses(func(status *AlertStatus) bool {
+		return status.Sent && status.Status == statusType
+	})
+}
+
+// GetSuppressedStatuses 获取仍处于告警且被上游主机抑制的记录
+func (d *AlertStatusManager) GetSuppressedStatuses() ([]*AlertStatus, error) {
+	return d.filterStatuses(func(status *AlertStatus) bool {
+		return status.SuppressedBy != "" && status.State() == StatusAlert
+	})
+}
+
+// filterStatuses 遍历所有告警状态，返回满足 match 的记录
+func (d *AlertStatusManager) filterStatuses(match func(status *AlertStatus) bool) ([]*AlertStatus, error) {
 	var statuses []*AlertStatus
 	err := d.db.View(func(txn *badger.Txn) error {
 		iter := txn.NewIterator(badger.DefaultIteratorOptions)
@@ -177,8 +483,7 @@ func (d *AlertStatusManager) GetAllUnsentStatuses(statusType StatusType) ([]*Ale
 				continue // 跳过有问题的记录，而不是终止整个操作
 			}
 
-			// 过滤条件：sent 为 false 且 status 为指定类型
-			if !status.Sent && status.Status == statusType {
+			if match(&status) {
 				statuses = append(statuses, &status) // 使用指针
 			}
 		}
@@ -190,6 +495,8 @@ func (d *AlertStatusManager) GetAllUnsentStatuses(statusType StatusType) ([]*Ale
 
 // MarkAsRecovered 标记主机为已恢复状态
 func (d *AlertStatusManager) MarkAsRecovered(status AlertStatus) error {
+	defer d.lock(status.Host)()
+
 	existingStatus, err := d.GetAlertStatus(status.Host)
 	if err != nil {
 		if err == badger.ErrKeyNotFound {
@@ -198,20 +505,30 @@ func (d *AlertStatusManager) MarkAsRecovered(status AlertStatus) error {
 		}
 		return fmt.Errorf("failed to get alert status: %w", err)
 	}
+	return d.transitionToRecovery(existingStatus, status)
+}
 
+// transitionToRecovery 将已有记录切换为 RECOVERY，调用方需持有记录的锁
+func (d *AlertStatusManager) transitionToRecovery(existingStatus AlertStatus, status AlertStatus) error {
 	// 如果之前是 RECOVERY 状态，跳过数据库操作
-	if existingStatus.Status == StatusRecovery {
+	current := currentState(existingStatus)
+	if current == StatusRecovery {
 		d.logger.Log(fmt.Sprintf("Host %s is already in RECOVERY state, skipping update", status.Host), "debug")
 		return nil
 	}
 
-	// 如果之前是 ALERT 状态，更新为 RECOVERY 状态并重置 sent 为 false
-	if existingStatus.Status == StatusAlert {
+	// 如果之前是 ALERT 或 DEGRADED 状态，更新为 RECOVERY 状态并重置 sent 为 false
+	// 确认信息保留在记录中，恢复通知中会注明确认人
+	if current == StatusAlert || current == StatusDegraded {
 		d.logger.Log(fmt.Sprintf("Marking host %s as RECOVERY", status.Host), "debug")
-		existingStatus.Status = StatusRecovery            // 更新为恢复状态
-		existingStatus.Sent = false                       // 恢复通知未发送
-		existingStatus.RecoveryTime = status.RecoveryTime // 设置恢复时间
-		return d.SetAlertStatus(existingStatus, d.dbConfig.Expire)
+		recovered := existingStatus
+		recovered.Status = StatusRecovery            // 更新为恢复状态
+		recovered.Sent = false                       // 恢复通知未发送
+		recovered.RecoveryTime = status.RecoveryTime // 设置恢复时间
+		recovered.FailStreak = 0
+		recovered.SuccessStreak = 0
+		recovered.AckState = ""
+		return d.saveTransition(existingStatus, recovered)
 	}
 
 	// 如果状态是其他未知状态，记录警告日志并跳过
@@ -221,6 +538,8 @@ func (d *AlertStatusManager) MarkAsRecovered(status AlertStatus) error {
 
 // UpdateSentStatus 更新主机的 Sent 状态
 func (d *AlertStatusManager) UpdateSentStatus(host string, sent bool) error {
+	defer d.lock(host)()
+
 	existingStatus, err := d.GetAlertStatus(host)
 	if err != nil {
 		if err == badger.ErrKeyNotFound {
@@ -232,7 +551,149 @@ func (d *AlertStatusManager) UpdateSentStatus(host string, sent bool) error {
 
 	// 更新 Sent 字段
 	existingStatus.Sent = sent
+	if sent {
+		existingStatus.Unannounced = false
+		existingStatus.LastNotifiedAt = time.Now().Format(time.RFC3339)
+		d.recordNotification(existingStatus, notificationType(existingStatus))
+	}
 
 	// 保存更新后的状态
 	return d.SetAlertStatus(existingStatus, d.dbConfig.Expire)
 }
+
+// MarkHandled 将记录标记为已处理但不记录通知，用于无需发送的通知，如从未通知过的故障的恢复
+func (d *AlertStatusManager) MarkHandled(host string) error {
+	defer d.lock(host)()
+
+	existingStatus, err := d.GetAlertStatus(host)
+	if err != nil {
+		return fmt.Errorf("failed to get alert status for host %s: %w", host, err)
+	}
+	existingStatus.Sent = true
+	return d.SetAlertStatus(existingStatus, d.dbConfig.Expire)
+}
+
+// MarkNotified 记录一次重复通知或升级通知的时间和升级级别
+func (d *AlertStatusManager) MarkNotified(host string, level int) error {
+	defer d.lock(host)()
+
+	existingStatus, err := d.GetAlertStatus(host)
+	if err != nil {
+		return fmt.Errorf("failed to get alert status for host %s: %w", host, err)
+	}
+
+	notification := "renotify"
+	if level > existingStatus.EscalationLevel {
+		notification = "escalation"
+	}
+	d.recordNotification(existingStatus, notification)
+
+	existingStatus.LastNotifiedAt = time.Now().Format(time.RFC3339)
+	existingStatus.EscalationLevel = level
+	return d.SetAlertStatus(existingStatus, d.dbConfig.Expire)
+}
+
+// Acknowledge 确认主机当前的告警（ALERT 或 DEGRADED），确认后不再重复通知和升级，直到恢复或取消确认
+func (d *AlertStatusManager) Acknowledge(host, by, comment string) (AlertStatus, error) {
+	defer d.lock(host)()
+
+	existingStatus, err := d.GetAlertStatus(host)
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return existingStatus, fmt.Errorf("host %s has no active alert", host)
+		}
+		return existingStatus, fmt.Errorf("failed to get alert status: %w", err)
+	}
+	if existingStatus.Status != StatusAlert && existingStatus.Status != StatusDegraded {
+		return existingStatus, fmt.Errorf("host %s is %s, only ALERT or DEGRADED can be acknowledged", host, existingStatus.Status)
+	}
+
+	existingStatus.AckState = existingStatus.Status
+	existingStatus.Status = StatusAcknowledged
+	existingStatus.AckBy = by
+	existingStatus.AckAt = time.Now().Format(time.RFC3339)
+	existingStatus.AckComment = comment
+	d.logger.Log(fmt.Sprintf("Alert of host %s acknowledged by %s", host, by), "info")
+	return existingStatus, d.SetAlertStatus(existingStatus, d.dbConfig.Expire)
+}
+
+// Unacknowledge 取消确认，主机回到实际状态并恢复重复通知和升级
+func (d *AlertStatusManager) Unacknowledge(host string) (AlertStatus, error) {
+	defer d.lock(host)()
+
+	existingStatus, err := d.GetAlertStatus(host)
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return existingStatus, fmt.Errorf("host %s has no active alert", host)
+		}
+		return existingStatus, fmt.Errorf("failed to get alert status: %w", err)
+	}
+	if existingStatus.Status != StatusAcknowledged {
+		return existingStatus, fmt.Errorf("alert of host %s is not acknowledged", host)
+	}
+
+	existingStatus.Status = existingStatus.AckState
+	existingStatus.AckState = ""
+	existingStatus.AckBy = ""
+	existingStatus.AckAt = ""
+	existingStatus.AckComment = ""
+	d.logger.Log(fmt.Sprintf("Alert of host %s unacknowledged", host), "info")
+	return existingStatus, d.SetAlertStatus(existingStatus, d.dbConfig.Expire)
+}
+
+// MarkSuppressed 记录主机因上游主机故障不可达，告警不再单独发送
+func (d *AlertStatusManager) MarkSuppressed(host, parent string) error {
+	defer d.lock(host)()
+
+	existingStatus, err := d.GetAlertStatus(host)
+	if err != nil {
+		return fmt.Errorf("failed to get alert status for host %s: %w", host, err)
+	}
+
+	existingStatus.SuppressedBy = parent
+	existingStatus.Reason = ReasonParentDown
+	existingStatus.Detail = fmt.Sprintf("unreachable due to parent %s", parent)
+	existingStatus.Sent = true
+	return d.SetAlertStatus(existingStatus, d.dbConfig.Expire)
+}
+
+// ReleaseSuppressed 上游主机恢复后解除对下游主机的抑制，仍处于告警的下游主机会重新发送告警
+// 已恢复的下游主机保留抑制标记，其恢复通知不单独发送
+func (d *AlertStatusManager) ReleaseSuppressed(parent string) error {
+	statuses, err := d.filterStatuses(func(status *AlertStatus) bool {
+		return status.SuppressedBy == parent
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		if err := d.releaseSuppressed(status.Host, parent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseSuppressed 在锁内重新读取下游主机的记录后解除抑制
+func (d *AlertStatusManager) releaseSuppressed(host, parent string) error {
+	defer d.lock(host)()
+
+	status, err := d.GetAlertStatus(host)
+	if err == badger.ErrKeyNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if status.SuppressedBy != parent || status.State() != StatusAlert {
+		return nil
+	}
+
+	status.SuppressedBy = ""
+	status.Sent = false
+	status.Reason = ReasonUnreachable
+	status.Detail = fmt.Sprintf("still unreachable after parent %s recovered", parent)
+	d.logger.Log(fmt.Sprintf("Host %s is still down after parent %s recovered", status.Host, parent), "info")
+	return d.SetAlertStatus(status, d.dbConfig.Expire)
+}
@@ -0,0 +1,260 @@
+package db
+
+import (
+	"easy-check/internal/config"
+	"easy-check/internal/logger"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// newTestLogger 创建只输出错误日志、文件写入临时目录的测试日志
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	return logger.NewLogger(logger.Config{
+		File:         filepath.Join(t.TempDir(), "test.log"),
+		ConsoleLevel: "error",
+		FileLevel:    "error",
+	})
+}
+
+func newTestAlertStatusManager(t *testing.T) *AlertStatusManager {
+	t.Helper()
+	instance, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatalf("open badger: %v", err)
+	}
+	t.Cleanup(func() { instance.Close() })
+
+	log := newTestLogger(t)
+	m, err := NewAlertStatusManager(instance, log, config.DbConfig{Expire: 3600})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	return m
+}
+
+func TestRecordFailureAndSuccessThresholds(t *testing.T) {
+	m := newTestAlertStatusManager(t)
+	host := "10.0.0.1"
+	alert := AlertStatus{Host: host, Status: StatusAlert, FailTime: "2026-01-01T00:00:00Z"}
+	recovery := AlertStatus{Host: host, Status: StatusRecovery, RecoveryTime: "2026-01-01T00:05:00Z"}
+
+	steps := []struct {
+		name       string
+		failure    bool
+		wantStatus StatusType
+		wantFail   int
+		wantOK     int
+	}{
+		{"first failure", true, StatusRecovery, 1, 0},
+		{"second failure", true, StatusRecovery, 2, 0},
+		{"success resets streak", false, StatusRecovery, 0, 0},
+		{"failure 1/3", true, StatusRecovery, 1, 0},
+		{"failure 2/3", true, StatusRecovery, 2, 0},
+		{"failure 3/3 alerts", true, StatusAlert, 3, 0},
+		{"still failing", true, StatusAlert, 3, 0},
+		{"success 1/2", false, StatusAlert, 0, 1},
+		{"failure keeps alert", true, StatusAlert, 1, 0},
+		{"success 1/2 again", false, StatusAlert, 0, 1},
+		{"success 2/2 recovers", false, StatusRecovery, 0, 0},
+	}
+
+	for _, step := range steps {
+		var err error
+		if step.failure {
+			err = m.RecordFailure(alert, 3)
+		} else {
+			err = m.RecordSuccess(recovery, 2)
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+
+		got, err := m.GetAlertStatus(host)
+		if err != nil {
+			t.Fatalf("%s: get status: %v", step.name, err)
+		}
+		if got.Status != step.wantStatus || got.FailStreak != step.wantFail || got.SuccessStreak != step.wantOK {
+			t.Errorf("%s: got status=%s fail=%d ok=%d, want status=%s fail=%d ok=%d",
+				step.name, got.Status, got.FailStreak, got.SuccessStreak, step.wantStatus, step.wantFail, step.wantOK)
+		}
+	}
+
+	got, _ := m.GetAlertStatus(host)
+	if got.Sent {
+		t.Error("recovery after an alert should be pending notification")
+	}
+}
+
+func TestRecordSuccessWithoutRecord(t *testing.T) {
+	m := newTestAlertStatusManager(t)
+	if err := m.RecordSuccess(AlertStatus{Host: "a", Status: StatusRecovery}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.GetAlertStatus("a"); err != badger.ErrKeyNotFound {
+		t.Errorf("healthy host should not create a record, got err=%v", err)
+	}
+}
+
+func TestRecordFailureThresholdOneSendsFirstAlert(t *testing.T) {
+	m := newTestAlertStatusManager(t)
+	alert := AlertStatus{Host: "a", Status: StatusAlert, FailTime: "2026-01-01T00:00:00Z"}
+	if err := m.RecordFailure(alert, 1); err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.GetAlertStatus("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusAlert || got.Sent {
+		t.Errorf("first alert should be pending notification, got status=%s sent=%v", got.Status, got.Sent)
+	}
+}
+
+func TestRecordFailureKeepsConcurrentUpdates(t *testing.T) {
+	m := newTestAlertStatusManager(t)
+	alert := AlertStatus{Host: "a", Status: StatusAlert, FailTime: "2026-01-01T00:00:00Z"}
+	if err := m.RecordFailure(alert, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if err := m.RecordFailure(alert, 1); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	if err := m.UpdateSentStatus("a", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Acknowledge("a", "ops", ""); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	got, err := m.GetAlertStatus("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Sent || got.AckState != StatusAlert || got.AckBy != "ops" {
+		t.Errorf("failures overwrote sent/ack: sent=%v ackState=%s ackBy=%s", got.Sent, got.AckState, got.AckBy)
+	}
+}
+
+func TestFlappingStartAndStop(t *testing.T) {
+	m := newTestAlertStatusManager(t)
+	m.SetFlapConfig(FlapConfig{Window: time.Minute, Threshold: 4})
+	host := "10.0.0.2"
+	alert := AlertStatus{Host: host, Status: StatusAlert, FailTime: "2026-01-01T00:00:00Z"}
+	recovery := AlertStatus{Host: host, Status: StatusRecovery, RecoveryTime: "2026-01-01T00:01:00Z"}
+
+	// 先创建正常状态的记录，再经过 告警 -> 恢复 -> 告警 -> 恢复，第 4 次切换进入抖动
+	if err := m.RecordFailure(alert, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RecordSuccess(recovery, 1); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := m.RecordFailure(alert, 1); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.RecordSuccess(recovery, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, _ := m.GetAlertStatus(host)
+	if got.Status != StatusFlapping || got.FlapState != StatusRecovery || got.Sent {
+		t.Fatalf("got status=%s flap_state=%s sent=%v, want unsent FLAPPING/RECOVERY", got.Status, got.FlapState, got.Sent)
+	}
+	if err := m.UpdateSentStatus(host, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// 抖动期间的切换只更新实际状态，不再产生通知
+	if err := m.RecordFailure(alert, 1); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = m.GetAlertStatus(host)
+	if got.Status != StatusFlapping || got.FlapState != StatusAlert || !got.Sent {
+		t.Fatalf("got status=%s flap_state=%s sent=%v, want sent FLAPPING/ALERT", got.Status, got.FlapState, got.Sent)
+	}
+	if alerts, _ := m.GetAllUnsentStatuses(StatusAlert); len(alerts) != 0 {
+		t.Errorf("got %d unsent alerts while flapping, want 0", len(alerts))
+	}
+
+	// 窗口内的切换过期后结束抖动，回到实际状态并待发送结束通知
+	got.Transitions = []string{time.Now().Add(-2 * time.Minute).Format(time.RFC3339)}
+	if err := m.SetAlertStatus(got, 3600); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RecordFailure(alert, 1); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = m.GetAlertStatus(host)
+	if got.Status != StatusAlert || !got.FlapStopped || got.Sent {
+		t.Fatalf("got status=%s flap_stopped=%v sent=%v, want unsent ALERT with flap stopped", got.Status, got.FlapStopped, got.Sent)
+	}
+	notices, _ := m.GetAllUnsentStatuses(StatusFlapping)
+	alerts, _ := m.GetAllUnsentStatuses(StatusAlert)
+	if len(notices) != 1 || len(alerts) != 0 {
+		t.Errorf("got %d flapping notices and %d alerts, want 1 and 0", len(notices), len(alerts))
+	}
+}
+
+func TestAcknowledge(t *testing.T) {
+	m := newTestAlertStatusManager(t)
+	host := "10.0.0.3"
+	alert := AlertStatus{Host: host, Status: StatusAlert, FailTime: "2026-01-01T00:00:00Z"}
+	recovery := AlertStatus{Host: host, Status: StatusRecovery, RecoveryTime: "2026-01-01T00:10:00Z"}
+
+	if _, err := m.Acknowledge(host, "alice", ""); err == nil {
+		t.Fatal("acknowledging a host without alert should fail")
+	}
+
+	m.RecordFailure(alert, 2)
+	m.RecordFailure(alert, 2)
+	m.UpdateSentStatus(host, true)
+	if _, err := m.Acknowledge(host, "alice", "rebooting switch"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 确认期间持续失败不改变状态，也不参与重复通知
+	m.RecordFailure(alert, 2)
+	got, _ := m.GetAlertStatus(host)
+	if got.Status != StatusAcknowledged || got.AckState != StatusAlert || got.AckBy != "alice" {
+		t.Fatalf("got status=%s ack_state=%s ack_by=%s, want ACKNOWLEDGED/ALERT by alice", got.Status, got.AckState, got.AckBy)
+	}
+	if alerts, _ := m.GetNotifiedStatuses(StatusAlert); len(alerts) != 0 {
+		t.Errorf("acknowledged alert is still eligible for re-notification")
+	}
+
+	// 取消确认后回到 ALERT
+	if _, err := m.Unacknowledge(host); err != nil {
+		t.Fatal(err)
+	}
+	if alerts, _ := m.GetNotifiedStatuses(StatusAlert); len(alerts) != 1 {
+		t.Errorf("unacknowledged alert should be eligible for re-notification again")
+	}
+
+	// 再次确认后恢复，恢复通知待发送且保留确认人
+	m.Acknowledge(host, "bob", "")
+	if err := m.RecordSuccess(recovery, 1); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = m.GetAlertStatus(host)
+	if got.Status != StatusRecovery || got.Sent || got.AckBy != "bob" {
+		t.Errorf("got status=%s sent=%v ack_by=%s, want unsent RECOVERY acknowledged by bob", got.Status, got.Sent, got.AckBy)
+	}
+}
@@ -0,0 +1,335 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+const (
+	incidentKeyPrefix      = "incident:"
+	incidentEventKeyPrefix = "incident_event:"
+)
+
+// incidentEventSeq 区分同一纳秒内追加的事件
+var incidentEventSeq atomic.Uint64
+
+// Incident 一次故障的完整记录，从进入 ALERT/DEGRADED 开始，到恢复时结束，不设过期时间
+// 故障打开后记录本身不再改写，期间的变化作为事件追加在 incident_event:<ID>| 前缀下，读取时依次合并
+type Incident struct {
+	ID            string                 `json:"id"`
+	Host          string                 `json:"host"`
+	Description   string                 `json:"description"`
+	Status        StatusType             `json:"status"` // 期间最严重的状态：ALERT 或 DEGRADED
+	Reason        ReasonType             `json:"reason"`
+	Detail        string                 `json:"detail"`
+	FailTime      string                 `json:"fail_time"`
+	RecoveryTime  string                 `json:"recovery_time"` // 为空表示仍未恢复
+	Duration      int64                  `json:"duration"`      // 持续时间（秒），恢复后写入
+	Notifications []IncidentNotification `json:"notifications"`
+	Timeline      []IncidentEvent        `json:"timeline"` // 期间的状态变化、通知和恢复，按时间排序
+}
+
+// 故障事件类型
+const (
+	incidentEventState        = "state"        // 在 ALERT 与 DEGRADED 之间切换
+	incidentEventNotification = "notification" // 发送了一次通知
+	incidentEventRecovered    = "recovered"    // 故障恢复
+)
+
+// IncidentEvent 故障期间的一次变化
+type IncidentEvent struct {
+	Type         string     `json:"type"` // state、notification、recovered
+	Time         string     `json:"time"`
+	Status       StatusType `json:"status,omitempty"`
+	Reason       ReasonType `json:"reason,omitempty"`
+	Detail       string     `json:"detail,omitempty"`
+	Notification string     `json:"notification,omitempty"` // 通知类型，同 IncidentNotification.Type
+	RecoveryTime string     `json:"recovery_time,omitempty"`
+}
+
+// apply 将事件合并到故障记录
+func (i *Incident) apply(event IncidentEvent) {
+	switch event.Type {
+	case incidentEventState:
+		// 记录期间最严重的状态
+		if event.Status == StatusAlert || i.Status != StatusAlert {
+			i.Status = event.Status
+			i.Reason = event.Reason
+			i.Detail = event.Detail
+		}
+	case incidentEventNotification:
+		i.Notifications = append(i.Notifications, IncidentNotification{Type: event.Notification, Time: event.Time})
+	case incidentEventRecovered:
+		i.RecoveryTime = event.RecoveryTime
+		failTime, err1 := time.Parse(time.RFC3339, i.FailTime)
+		recovered, err2 := time.Parse(time.RFC3339, event.RecoveryTime)
+		if err1 == nil && err2 == nil {
+			i.Duration = int64(recovered.Sub(failTime).Seconds())
+		}
+	}
+	i.Timeline = append(i.Timeline, event)
+}
+
+// IncidentNotification 故障期间发送的一次通知
+type IncidentNotification struct {
+	Type string `json:"type"` // alert、degraded、recovery、flapping、renotify、escalation
+	Time string `json:"time"`
+}
+
+// IncidentStats 单个主机在统计范围内的故障指标
+type IncidentStats struct {
+	Host          string  `json:"host"`
+	Count         int     `json:"count"`          // 故障次数
+	Open          int     `json:"open"`           // 仍未恢复的故障数
+	TotalDowntime int64   `json:"total_downtime"` // 已恢复故障的总持续时间（秒）
+	MTTR          float64 `json:"mttr"`           // 平均恢复时间（秒）
+	MTBF          float64 `json:"mtbf"`           // 平均故障间隔（秒）：上一次恢复到下一次故障的平均时长
+}
+
+// isProblem 是否为需要记录故障的状态
+func isProblem(state StatusType) bool {
+	return state == StatusAlert || state == StatusDegraded
+}
+
+// trackIncident 根据状态切换打开、更新或关闭故障记录，故障记录写入失败不影响状态保存
+func (d *AlertStatusManager) trackIncident(prev AlertStatus, next *AlertStatus) {
+	prevProblem := isProblem(currentState(prev))
+	nextProblem := isProblem(next.Status)
+	if next.IncidentID == "" {
+		next.IncidentID = prev.IncidentID
+	}
+
+	var err error
+	switch {
+	case !prevProblem && nextProblem:
+		err = d.openIncident(next)
+	case prevProblem && nextProblem:
+		err = d.appendIncidentEvent(next.IncidentID, IncidentEvent{
+			Type:   incidentEventState,
+			Status: next.Status,
+			Reason: next.Reason,
+			Detail: next.Detail,
+		})
+	case prevProblem && !nextProblem:
+		recoveryTime := next.RecoveryTime
+		if recoveryTime == "" {
+			recoveryTime = time.Now().Format(time.RFC3339)
+		}
+		err = d.appendIncidentEvent(next.IncidentID, IncidentEvent{
+			Type:         incidentEventRecovered,
+			RecoveryTime: recoveryTime,
+		})
+	}
+	if err != nil {
+		d.logger.Log(fmt.Sprintf("Failed to track incident for host %s: %v", next.Host, err), "error")
+	}
+}
+
+// openIncident 新建故障记录并把 ID 写回状态
+func (d *AlertStatusManager) openIncident(status *AlertStatus) error {
+	failTime, err := time.Parse(time.RFC3339, status.FailTime)
+	if err != nil {
+		failTime = time.Now()
+	}
+
+	incident := Incident{
+		ID:          fmt.Sprintf("%s|%019d", status.Host, failTime.UnixNano()),
+		Host:        status.Host,
+		Description: status.Description,
+		Status:      status.Status,
+		Reason:      status.Reason,
+		Detail:      status.Detail,
+		FailTime:    failTime.Format(time.RFC3339),
+	}
+	status.IncidentID = incident.ID
+	d.logger.Log(fmt.Sprintf("Opened incident %s", incident.ID), "debug")
+	return d.saveIncident(incident)
+}
+
+// recordNotification 在状态关联的故障记录中追加一次通知
+func (d *AlertStatusManager) recordNotification(status AlertStatus, notificationType string) {
+	if status.IncidentID == "" {
+		return
+	}
+	err := d.appendIncidentEvent(status.IncidentID, IncidentEvent{
+		Type:         incidentEventNotification,
+		Notification: notificationType,
+	})
+	if err != nil {
+		d.logger.Log(fmt.Sprintf("Failed to record notification for incident %s: %v", status.IncidentID, err), "error")
+	}
+}
+
+// notificationType 根据状态返回通知类型
+func notificationType(status AlertStatus) string {
+	if status.IsFlapNotice() {
+		return "flapping"
+	}
+	return strings.ToLower(string(status.Status))
+}
+
+// appendIncidentEvent 在故障记录后追加一条事件，每条事件单独一个键，并发写入不会互相覆盖
+func (d *AlertStatusManager) appendIncidentEvent(id string, event IncidentEvent) error {
+	if id == "" {
+		return nil // 升级前创建的状态没有关联的故障记录
+	}
+	now := time.Now()
+	if event.Time == "" {
+		event.Time = now.Format(time.RFC3339)
+	}
+	value, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal incident event: %w", err)
+	}
+	key := fmt.Sprintf("%s%s|%019d|%019d", incidentEventKeyPrefix, id, now.UnixNano(), incidentEventSeq.Add(1))
+	return d.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(key), value)
+	})
+}
+
+// loadIncidentEvents 按追加顺序合并故障的事件
+func loadIncidentEvents(txn *badger.Txn, incident *Incident) error {
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = []byte(incidentEventKeyPrefix + incident.ID + "|")
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	for it.Rewind(); it.Valid(); it.Next() {
+		var event IncidentEvent
+		if err := it.Item().Value(func(v []byte) error {
+			return json.Unmarshal(v, &event)
+		}); err != nil {
+			return fmt.Errorf("failed to decode incident event %s: %w", string(it.Item().Key()), err)
+		}
+		incident.apply(event)
+	}
+	return nil
+}
+
+func (d *AlertStatusManager) saveIncident(incident Incident) error {
+	value, err := json.Marshal(incident)
+	if err != nil {
+		return fmt.Errorf("failed to marshal incident: %w", err)
+	}
+	return d.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(incidentKeyPrefix+incident.ID), value)
+	})
+}
+
+// GetIncidents 查询与 [start, end] 有重叠的故障记录，host 为空时查询所有主机，start/end 为零值时不限制
+// 结果按开始时间倒序排列
+func (d *AlertStatusManager) GetIncidents(host string, start, end time.Time) ([]Incident, error) {
+	prefix := incidentKeyPrefix
+	if host != "" {
+		prefix += host + "|"
+	}
+
+	var incidents []Incident
+	err := d.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			var incident Incident
+			if err := it.Item().Value(func(v []byte) error {
+				return json.Unmarshal(v, &incident)
+			}); err != nil {
+				d.logger.Log(fmt.Sprintf("Failed to decode incident %s: %v", string(it.Item().Key()), err), "error")
+				continue
+			}
+			if err := loadIncidentEvents(txn, &incident); err != nil {
+				d.logger.Log(err.Error(), "error")
+			}
+			if incident.overlaps(start, end) {
+				incidents = append(incidents, incident)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incidents: %w", err)
+	}
+
+	sort.Slice(incidents, func(i, j int) bool {
+		return incidents[i].FailTime > incidents[j].FailTime
+	})
+	return incidents, nil
+}
+
+// overlaps 故障期间是否与 [start, end] 有重叠
+func (i *Incident) overlaps(start, end time.Time) bool {
+	failTime, err := time.Parse(time.RFC3339, i.FailTime)
+	if err != nil {
+		return false
+	}
+	if !end.IsZero() && failTime.After(end) {
+		return false
+	}
+	if start.IsZero() || i.RecoveryTime == "" {
+		return true
+	}
+	recoveryTime, err := time.Parse(time.RFC3339, i.RecoveryTime)
+	return err != nil || !recoveryTime.Before(start)
+}
+
+// GetIncidentStats 按主机统计 [start, end] 内的故障次数、MTTR 和 MTBF
+func (d *AlertStatusManager) GetIncidentStats(host string, start, end time.Time) ([]IncidentStats, error) {
+	incidents, err := d.GetIncidents(host, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return computeIncidentStats(incidents), nil
+}
+
+// computeIncidentStats 按主机汇总故障指标，结果按主机排序
+func computeIncidentStats(incidents []Incident) []IncidentStats {
+	byHost := make(map[string][]Incident)
+	for _, incident := range incidents {
+		byHost[incident.Host] = append(byHost[incident.Host], incident)
+	}
+
+	stats := make([]IncidentStats, 0, len(byHost))
+	for host, list := range byHost {
+		sort.Slice(list, func(i, j int) bool { return list[i].FailTime < list[j].FailTime })
+
+		s := IncidentStats{Host: host, Count: len(list)}
+		closed := 0
+		var uptime int64
+		uptimes := 0
+		for i, incident := range list {
+			if incident.RecoveryTime == "" {
+				s.Open++
+			} else {
+				closed++
+				s.TotalDowntime += incident.Duration
+			}
+			if i == 0 || list[i-1].RecoveryTime == "" {
+				continue
+			}
+			prevRecovery, err1 := time.Parse(time.RFC3339, list[i-1].RecoveryTime)
+			failTime, err2 := time.Parse(time.RFC3339, incident.FailTime)
+			if err1 == nil && err2 == nil {
+				uptime += int64(failTime.Sub(prevRecovery).Seconds())
+				uptimes++
+			}
+		}
+		if closed > 0 {
+			s.MTTR = float64(s.TotalDowntime) / float64(closed)
+		}
+		if uptimes > 0 {
+			s.MTBF = float64(uptime) / float64(uptimes)
+		}
+		stats = append(stats, s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Host < stats[j].Host })
+	return stats
+}
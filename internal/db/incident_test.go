@@ -0,0 +1,106 @@
+package db
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIncidentLifecycle(t *testing.T) {
+	m := newTestAlertStatusManager(t)
+	host := "10.0.0.4"
+	failTime := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	recoveryTime := failTime.Add(5 * time.Minute)
+
+	// 先创建正常状态的记录，再降级、告警、恢复，整个过程为一次故障
+	m.RecordFailure(AlertStatus{Host: host, Status: StatusAlert}, 2)
+	m.RecordSuccess(AlertStatus{Host: host, Status: StatusRecovery}, 1)
+	m.RecordFailure(AlertStatus{Host: host, Status: StatusDegraded, Reason: ReasonHighLatency, FailTime: failTime.Format(time.RFC3339)}, 1)
+	m.UpdateSentStatus(host, true)
+	m.RecordFailure(AlertStatus{Host: host, Status: StatusAlert, Reason: ReasonUnreachable, FailTime: failTime.Add(time.Minute).Format(time.RFC3339)}, 1)
+	m.UpdateSentStatus(host, true)
+	m.MarkNotified(host, 1)
+	m.RecordSuccess(AlertStatus{Host: host, Status: StatusRecovery, RecoveryTime: recoveryTime.Format(time.RFC3339)}, 1)
+	m.UpdateSentStatus(host, true)
+
+	incidents, err := m.GetIncidents(host, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(incidents) != 1 {
+		t.Fatalf("got %d incidents, want 1", len(incidents))
+	}
+	incident := incidents[0]
+	if incident.Status != StatusAlert || incident.Reason != ReasonUnreachable || incident.Duration != 300 {
+		t.Errorf("got status=%s reason=%s duration=%d, want ALERT/unreachable/300", incident.Status, incident.Reason, incident.Duration)
+	}
+	var types []string
+	for _, n := range incident.Notifications {
+		types = append(types, n.Type)
+	}
+	if got, want := strings.Join(types, ","), "degraded,alert,escalation,recovery"; got != want {
+		t.Errorf("got notifications %s, want %s", got, want)
+	}
+	var timeline []string
+	for _, e := range incident.Timeline {
+		timeline = append(timeline, e.Type)
+	}
+	if got, want := strings.Join(timeline, ","), "notification,state,notification,notification,recovered,notification"; got != want {
+		t.Errorf("got timeline %s, want %s", got, want)
+	}
+
+	// 时间范围过滤
+	if got, _ := m.GetIncidents(host, recoveryTime.Add(time.Minute), time.Time{}); len(got) != 0 {
+		t.Errorf("incident recovered before start should be filtered, got %d", len(got))
+	}
+	if got, _ := m.GetIncidents("other", time.Time{}, time.Time{}); len(got) != 0 {
+		t.Errorf("host filter returned %d incidents for other host", len(got))
+	}
+}
+
+func TestIncidentConcurrentNotifications(t *testing.T) {
+	m := newTestAlertStatusManager(t)
+	status := AlertStatus{Host: "a", Status: StatusAlert, FailTime: time.Now().Format(time.RFC3339)}
+	m.trackIncident(AlertStatus{}, &status)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.recordNotification(status, "renotify")
+		}()
+	}
+	wg.Wait()
+
+	incidents, err := m.GetIncidents("a", time.Time{}, time.Time{})
+	if err != nil || len(incidents) != 1 {
+		t.Fatalf("got %d incidents, err=%v", len(incidents), err)
+	}
+	if got := len(incidents[0].Notifications); got != 20 {
+		t.Errorf("got %d notifications, want 20", got)
+	}
+}
+
+func TestComputeIncidentStats(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) string { return base.Add(time.Duration(minutes) * time.Minute).Format(time.RFC3339) }
+
+	stats := computeIncidentStats([]Incident{
+		{Host: "a", FailTime: at(0), RecoveryTime: at(10), Duration: 600},
+		{Host: "a", FailTime: at(70), RecoveryTime: at(90), Duration: 1200},
+		{Host: "a", FailTime: at(150)},
+		{Host: "b", FailTime: at(0), RecoveryTime: at(1), Duration: 60},
+	})
+	if len(stats) != 2 {
+		t.Fatalf("got %d hosts, want 2", len(stats))
+	}
+	a := stats[0]
+	if a.Count != 3 || a.Open != 1 || a.TotalDowntime != 1800 || a.MTTR != 900 || a.MTBF != 3600 {
+		t.Errorf("host a: got %+v, want count=3 open=1 downtime=1800 mttr=900 mtbf=3600", a)
+	}
+	if b := stats[1]; b.Count != 1 || b.MTTR != 60 || b.MTBF != 0 {
+		t.Errorf("host b: got %+v", b)
+	}
+}
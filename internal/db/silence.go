@@ -0,0 +1,244 @@
+package db
+
+import (
+	"crypto/rand"
+	"easy-check/internal/logger"
+	"easy-check/internal/utils"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+const (
+	silenceKeyPrefix   = "silence:"
+	maxSilenceDuration = 7 * 24 * 60 // 周期性静默单次最长持续时间（分钟）
+)
+
+// Silence 静默规则（维护窗口）：匹配的主机在生效期间仍记录状态，但不发送通知
+// 一次性静默在 StartsAt 至 EndsAt 之间生效；
+// 周期性静默在每次命中 Cron 后持续 Duration 分钟，StartsAt/EndsAt 可选，用于限定整体生效范围
+type Silence struct {
+	ID        string   `json:"id"`
+	Hosts     []string `json:"hosts"` // 主机匹配模式，支持通配符，如 10.0.0.*
+	Tags      []string `json:"tags"`  // 匹配带有任一标签的主机
+	StartsAt  string   `json:"starts_at"`
+	EndsAt    string   `json:"ends_at"`
+	Cron      string   `json:"cron"`     // 周期性维护窗口的开始时间（分 时 日 月 周）
+	Duration  int      `json:"duration"` // 周期性维护窗口的持续时间（分钟）
+	Comment   string   `json:"comment"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// Validate 校验静默规则
+func (s *Silence) Validate() error {
+	if len(s.Hosts) == 0 && len(s.Tags) == 0 {
+		return errors.New("silence must match at least one host pattern or tag")
+	}
+	for _, pattern := range s.Hosts {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid host pattern %q: %w", pattern, err)
+		}
+	}
+
+	start, end, err := s.timeRange()
+	if err != nil {
+		return err
+	}
+	if !start.IsZero() && !end.IsZero() && !end.After(start) {
+		return errors.New("ends_at must be after starts_at")
+	}
+
+	if s.Cron == "" {
+		if start.IsZero() || end.IsZero() {
+			return errors.New("one-off silence requires starts_at and ends_at")
+		}
+		return nil
+	}
+	if _, err := utils.ParseCron(s.Cron); err != nil {
+		return err
+	}
+	if s.Duration <= 0 || s.Duration > maxSilenceDuration {
+		return fmt.Errorf("duration must be between 1 and %d minutes", maxSilenceDuration)
+	}
+	return nil
+}
+
+// ActiveAt 判断静默在 now 时是否生效
+func (s *Silence) ActiveAt(now time.Time) bool {
+	start, end, err := s.timeRange()
+	if err != nil {
+		return false
+	}
+	if (!start.IsZero() && now.Before(start)) || (!end.IsZero() && now.After(end)) {
+		return false
+	}
+	if s.Cron == "" {
+		return !start.IsZero() && !end.IsZero()
+	}
+
+	schedule, err := utils.ParseCron(s.Cron)
+	if err != nil {
+		return false
+	}
+	// 向前查找 Duration 分钟内是否有窗口开始
+	minute := now.Truncate(time.Minute)
+	for i := 0; i < s.Duration && i < maxSilenceDuration; i++ {
+		if schedule.Match(minute.Add(-time.Duration(i) * time.Minute)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches 判断主机是否命中静默规则
+func (s *Silence) Matches(host string, tags []string) bool {
+	for _, pattern := range s.Hosts {
+		if ok, _ := path.Match(pattern, host); ok {
+			return true
+		}
+	}
+	for _, want := range s.Tags {
+		for _, tag := range tags {
+			if tag == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Silence) timeRange() (start, end time.Time, err error) {
+	if s.StartsAt != "" {
+		if start, err = time.Parse(time.RFC3339, s.StartsAt); err != nil {
+			return start, end, fmt.Errorf("invalid starts_at: %w", err)
+		}
+	}
+	if s.EndsAt != "" {
+		if end, err = time.Parse(time.RFC3339, s.EndsAt); err != nil {
+			return start, end, fmt.Errorf("invalid ends_at: %w", err)
+		}
+	}
+	return start, end, nil
+}
+
+// SilenceManager 提供静默规则的存储和查询
+type SilenceManager struct {
+	db     *badger.DB
+	logger *logger.Logger
+}
+
+// NewSilenceManager 创建一个新的 SilenceManager
+func NewSilenceManager(dbInstance *badger.DB, logger *logger.Logger) (*SilenceManager, error) {
+	if dbInstance == nil {
+		return nil, logger.LogAndError("DBInstance is nil, cannot create SilenceManager", "error")
+	}
+	return &SilenceManager{db: dbInstance, logger: logger}, nil
+}
+
+// SaveSilence 新增或更新静默规则，ID 为空时自动生成
+// 设置了结束时间的静默在结束一天后自动过期删除
+func (m *SilenceManager) SaveSilence(s Silence) (Silence, error) {
+	if err := s.Validate(); err != nil {
+		return s, err
+	}
+	if s.ID == "" {
+		id := make([]byte, 8)
+		if _, err := rand.Read(id); err != nil {
+			return s, fmt.Errorf("failed to generate silence id: %w", err)
+		}
+		s.ID = hex.EncodeToString(id)
+	}
+	if s.CreatedAt == "" {
+		s.CreatedAt = time.Now().Format(time.RFC3339)
+	}
+
+	value, err := json.Marshal(s)
+	if err != nil {
+		return s, fmt.Errorf("failed to marshal silence: %w", err)
+	}
+
+	entry := badger.NewEntry([]byte(silenceKeyPrefix+s.ID), value)
+	if _, end, _ := s.timeRange(); !end.IsZero() {
+		ttl := time.Until(end) + 24*time.Hour
+		if ttl <= 0 {
+			return s, errors.New("silence has already ended")
+		}
+		entry = entry.WithTTL(ttl)
+	}
+
+	err = m.db.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(entry)
+	})
+	if err != nil {
+		return s, fmt.Errorf("failed to save silence: %w", err)
+	}
+	m.logger.Log(fmt.Sprintf("Saved silence %s for hosts %v tags %v", s.ID, s.Hosts, s.Tags), "info")
+	return s, nil
+}
+
+// DeleteSilence 删除静默规则
+func (m *SilenceManager) DeleteSilence(id string) error {
+	err := m.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(silenceKeyPrefix + id))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete silence %s: %w", id, err)
+	}
+	m.logger.Log(fmt.Sprintf("Deleted silence %s", id), "info")
+	return nil
+}
+
+// ListSilences 返回所有静默规则，按创建时间排序
+func (m *SilenceManager) ListSilences() ([]Silence, error) {
+	var silences []Silence
+
+	err := m.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(silenceKeyPrefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			var s Silence
+			err := it.Item().Value(func(v []byte) error {
+				return json.Unmarshal(v, &s)
+			})
+			if err != nil {
+				m.logger.Log(fmt.Sprintf("Failed to decode silence %s: %v", string(it.Item().Key()), err), "error")
+				continue
+			}
+			silences = append(silences, s)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list silences: %w", err)
+	}
+
+	sort.Slice(silences, func(i, j int) bool {
+		return silences[i].CreatedAt < silences[j].CreatedAt
+	})
+	return silences, nil
+}
+
+// ActiveSilences 返回 now 时生效的静默规则
+func (m *SilenceManager) ActiveSilences(now time.Time) ([]Silence, error) {
+	silences, err := m.ListSilences()
+	if err != nil {
+		return nil, err
+	}
+
+	var active []Silence
+	for _, s := range silences {
+		if s.ActiveAt(now) {
+			active = append(active, s)
+		}
+	}
+	return active, nil
+}
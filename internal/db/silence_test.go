@@ -0,0 +1,90 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSilenceActiveAndMatches(t *testing.T) {
+	now := time.Date(2026, 1, 3, 2, 30, 0, 0, time.Local) // 周六
+
+	oneOff := Silence{
+		Hosts:    []string{"10.0.0.*"},
+		StartsAt: now.Add(-time.Hour).Format(time.RFC3339),
+		EndsAt:   now.Add(time.Hour).Format(time.RFC3339),
+	}
+	if err := oneOff.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if !oneOff.ActiveAt(now) || oneOff.ActiveAt(now.Add(2*time.Hour)) {
+		t.Error("one-off silence should only be active within its time range")
+	}
+	if !oneOff.Matches("10.0.0.8", nil) || oneOff.Matches("10.0.1.8", nil) {
+		t.Error("host pattern should match 10.0.0.8 only")
+	}
+
+	// 每周六 02:00 开始，持续 60 分钟
+	weekly := Silence{Tags: []string{"db"}, Cron: "0 2 * * 6", Duration: 60}
+	if err := weekly.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if !weekly.ActiveAt(now) {
+		t.Error("weekly silence should be active at 02:30 on Saturday")
+	}
+	if weekly.ActiveAt(now.Add(time.Hour)) || weekly.ActiveAt(now.Add(24*time.Hour)) {
+		t.Error("weekly silence should not be active outside its window")
+	}
+	if !weekly.Matches("any", []string{"web", "db"}) || weekly.Matches("any", []string{"web"}) {
+		t.Error("tag should match hosts tagged db only")
+	}
+
+	for _, s := range []Silence{
+		{StartsAt: oneOff.StartsAt, EndsAt: oneOff.EndsAt},
+		{Hosts: []string{"a"}, StartsAt: oneOff.StartsAt},
+		{Hosts: []string{"a"}, StartsAt: oneOff.EndsAt, EndsAt: oneOff.StartsAt},
+		{Hosts: []string{"a"}, Cron: "0 2 * * 6"},
+		{Hosts: []string{"a"}, Cron: "bad", Duration: 10},
+	} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("silence %+v should be invalid", s)
+		}
+	}
+}
+
+func TestSilenceManager(t *testing.T) {
+	status := newTestAlertStatusManager(t)
+	m, err := NewSilenceManager(status.db, status.logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	saved, err := m.SaveSilence(Silence{
+		Hosts:    []string{"a"},
+		StartsAt: now.Add(-time.Minute).Format(time.RFC3339),
+		EndsAt:   now.Add(time.Hour).Format(time.RFC3339),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.ID == "" {
+		t.Fatal("saved silence has no id")
+	}
+	// 2 月 30 日不存在，该周期性静默不会生效
+	if _, err := m.SaveSilence(Silence{Hosts: []string{"b"}, Cron: "0 3 30 2 *", Duration: 30}); err != nil {
+		t.Fatal(err)
+	}
+
+	all, _ := m.ListSilences()
+	active, _ := m.ActiveSilences(now)
+	if len(all) != 2 || len(active) != 1 || active[0].ID != saved.ID {
+		t.Fatalf("got %d silences and %d active, want 2 and the one-off silence", len(all), len(active))
+	}
+
+	if err := m.DeleteSilence(saved.ID); err != nil {
+		t.Fatal(err)
+	}
+	if all, _ := m.ListSilences(); len(all) != 1 {
+		t.Errorf("got %d silences after delete, want 1", len(all))
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -136,6 +137,16 @@ func (t *TSDB) AppendMetrics(metrics map[string]float64, timestamp int64, labels
 	return nil
 }
 
+// hostSelector 生成精确匹配多个主机的 host 标签选择器
+// 主机标识可能是 URL 等含正则特殊字符的字符串，逐个转义后再作为 PromQL 字符串引用
+func hostSelector(hosts []string) string {
+	patterns := make([]string, len(hosts))
+	for i, h := range hosts {
+		patterns[i] = regexp.QuoteMeta(h)
+	}
+	return "host=~" + strconv.Quote(strings.Join(patterns, "|"))
+}
+
 // QueryLatestMetricsForHosts 使用 PromQL 查询多个主机的最新监控数据
 func (t *TSDB) QueryLatestMetricsForHosts(hosts []string, metric string) (map[string]float64, error) {
 	result := make(map[string]float64)
@@ -147,14 +158,8 @@ func (t *TSDB) QueryLatestMetricsForHosts(hosts []string, metric string) (map[st
 
 	// 构造 PromQL 表达式
 	// 例如 metric{host=~"host1|host2|host3"}
-	var hostFilter string
-	for i, h := range hosts {
-		if i > 0 {
-			hostFilter += "|"
-		}
-		hostFilter += h // 直接拼接主机名
-	}
-	expr := fmt.Sprintf(`%s{host=~"%s"}`, metric, hostFilter) // 将整个正则表达式用双引号包裹
+	// 同一主机可能因 ip_version 等标签存在多条序列，按 host 聚合
+	expr := fmt.Sprintf(`max by (host) (%s{%s})`, metric, hostSelector(hosts))
 
 	// 指定查询时间点
 	queryTime := time.Now()
@@ -198,14 +203,7 @@ func (t *TSDB) QueryRangeMetricsForHosts(hosts []string, metric string, startTim
 	queryable := t.db
 
 	// 构造 PromQL 表达式
-	var hostFilter string
-	for i, h := range hosts {
-		if i > 0 {
-			hostFilter += "|"
-		}
-		hostFilter += h
-	}
-	expr := fmt.Sprintf(`%s{host=~"%s"}`, metric, hostFilter)
+	expr := fmt.Sprintf(`max by (host) (%s{%s})`, metric, hostSelector(hosts))
 
 	// 创建范围查询
 	ctx := context.Background()
@@ -245,6 +243,46 @@ func (t *TSDB) QueryRangeMetricsForHosts(hosts []string, metric string, startTim
 	return result, nil
 }
 
+// QueryOverTime 按主机汇总 [startTime, endTime] 内的样本，function 为 count_over_time（样本数，即检测次数）
+// 或 sum_over_time（样本值之和）
+func (t *TSDB) QueryOverTime(function string, hosts []string, metric string, startTime, endTime time.Time) (map[string]int, error) {
+	result := make(map[string]int)
+	seconds := int64(endTime.Sub(startTime).Seconds())
+	if len(hosts) == 0 || seconds <= 0 {
+		return result, nil
+	}
+	// 范围向量会加载范围内的全部样本，一个月的数据远超即时查询的样本数
+	engine := promql.NewEngine(promql.EngineOpts{
+		MaxSamples: 10000000,
+		Timeout:    30 * time.Second,
+	})
+
+	expr := fmt.Sprintf(`sum by (host) (%s(%s{%s}[%ds]))`, function, metric, hostSelector(hosts), seconds)
+
+	ctx := context.Background()
+	q, err := engine.NewInstantQuery(ctx, t.db, nil, expr, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PromQL query: %v", err)
+	}
+	defer q.Close()
+
+	res := q.Exec(ctx)
+	if res.Err != nil {
+		return nil, fmt.Errorf("failed to execute PromQL query: %v", res.Err)
+	}
+
+	vector, ok := res.Value.(promql.Vector)
+	if !ok {
+		return nil, fmt.Errorf("unexpected query result type: %T", res.Value)
+	}
+	for _, sample := range vector {
+		if host := sample.Metric.Get("host"); host != "" {
+			result[host] = int(sample.F)
+		}
+	}
+	return result, nil
+}
+
 // TimeSeriesPoint 时间序列数据点
 type TimeSeriesPoint struct {
 	Timestamp int64   `json:"timestamp"` // 毫秒时间戳
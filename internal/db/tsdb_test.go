@@ -0,0 +1,32 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+func TestHostSelector(t *testing.T) {
+	hosts := []string{"10.0.0.1", "https://example.com/health?a=1+2", "dns://8.8.8.8/example.com/A"}
+	matchers, err := parser.ParseMetricSelector("packet_loss{" + hostSelector(hosts) + "}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var host *labels.Matcher
+	for _, m := range matchers {
+		if m.Name == "host" {
+			host = m
+		}
+	}
+	for _, h := range hosts {
+		if !host.Matches(h) {
+			t.Errorf("selector does not match %q", h)
+		}
+	}
+	for _, h := range []string{"10.0.0.12", "10a0b0c1", "https://example.com/health?a=1 2"} {
+		if host.Matches(h) {
+			t.Errorf("selector unexpectedly matches %q", h)
+		}
+	}
+}
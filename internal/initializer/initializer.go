@@ -36,6 +36,7 @@ type AppContext struct {
 	DB               *db.DB
 	TSDB             *db.TSDB
 	AlertStatusMgr   *db.AlertStatusManager
+	SilenceMgr       *db.SilenceManager
 	AggregatorHandle types.AggregatorHandle
 }
 
@@ -105,6 +106,12 @@ func Initialize(machineID, version string) (*AppContext, error) {
 		return nil, fmt.Errorf("failed to create alert status manager: %w", err)
 	}
 
+	// 创建 SilenceManager
+	silenceMgr, err := db.NewSilenceManager(dbInstance.Instance, appLogger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create silence manager: %w", err)
+	}
+
 	os := runtime.GOOS
 	arch := runtime.GOARCH
 	platformInfo := PlatformInfo{
@@ -125,6 +132,7 @@ func Initialize(machineID, version string) (*AppContext, error) {
 		DB:               dbInstance,
 		TSDB:             tsdbInstance,
 		AlertStatusMgr:   alertStatusMgr,
+		SilenceMgr:       silenceMgr,
 		AggregatorHandle: aggregatorHandle,
 	}
 
@@ -132,7 +140,7 @@ func Initialize(machineID, version string) (*AppContext, error) {
 
 	// 初始化心跳模块（避免循环导入，直接传入配置值）
 	// 根据版本自动切换服务器地址和心跳间隔
-	isDev := version == "dev"
+	isDev = version == "dev"
 	heartbeatEnabled := true
 	serverBaseURL := "https://easy-check-server.ygqygq2.com"
 	heartbeatAPI := "/api/heartbeat"
@@ -216,6 +224,9 @@ func initLogger(cfg *config.Config, defaultLogger *logger.Logger) *logger.Logger
 // RegisterNotifiers 注册所有支持的通知器
 func RegisterNotifiers(logger *logger.Logger) {
 	notifier.RegisterNotifier("feishu", notifier.NewFeishuNotifier)
+	notifier.RegisterNotifier("dingtalk", notifier.NewDingTalkNotifier)
+	notifier.RegisterNotifier("wecom", notifier.NewWeComNotifier)
+	notifier.RegisterNotifier("email", notifier.NewEmailNotifier)
 	// 可以在这里添加其他通知器的注册
 	logger.Log("All notifiers registered successfully", "debug")
 }
@@ -243,6 +254,7 @@ func initializeAlertAggregator(cfg *config.Config, baseNotifier types.Notifier,
 		aggregatorHandle = aggregator.NewAggregator(
 			cfg.Alert.AggregateAlertLineTemplate,
 			cfg.Alert.AggregateRecoveryLineTemplate,
+			cfg.Alert.AggregateDegradedLineTemplate,
 			baseNotifier,
 			logger,
 			window,
@@ -1,10 +1,12 @@
 package notifier
 
 import (
+	"easy-check/internal/config"
 	"easy-check/internal/db"
 	"easy-check/internal/logger"
 	"easy-check/internal/types"
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -13,16 +15,25 @@ type Consumer struct {
 	logger   *logger.Logger
 	interval time.Duration
 	handler  types.AggregatorHandle
+
+	silences *db.SilenceManager // 为 nil 时不检查静默
+
+	configMu sync.RWMutex
+	policy   *RenotifyPolicy        // 重复通知与升级策略，nil 表示不启用
+	hosts    map[string]config.Host // 按检测标识（config.Host.Key）索引的主机配置，用于匹配静默规则
+	hostDeps map[string][]string    // 检测依赖的上游检测，用于抑制下游告警
 }
 
 func NewConsumer(
 	db *db.AlertStatusManager,
+	silences *db.SilenceManager,
 	logger *logger.Logger,
 	interval time.Duration,
 	handler types.AggregatorHandle,
 ) *Consumer {
 	return &Consumer{
 		db:       db,
+		silences: silences,
 		logger:   logger,
 		interval: interval,
 		handler:  handler,
@@ -34,8 +45,129 @@ func (c *Consumer) Start() {
 	defer ticker.Stop()
 
 	for range ticker.C {
+		c.releaseSuppressed()
 		c.processEvents(db.StatusAlert, "alerts")
+		c.processEvents(db.StatusDegraded, "degradations")
 		c.processEvents(db.StatusRecovery, "recoveries")
+		c.processEvents(db.StatusFlapping, "flapping")
+		c.processRenotify(time.Now())
+	}
+}
+
+// UpdateConfig 根据配置重建重复通知与升级策略，并更新主机标签和依赖关系
+func (c *Consumer) UpdateConfig(cfg *config.Config) {
+	policy := NewRenotifyPolicy(cfg, c.logger)
+	hosts := make(map[string]config.Host, len(cfg.Hosts))
+	hostDeps := make(map[string][]string)
+	for _, host := range cfg.Hosts {
+		hosts[host.Key()] = host
+		if len(host.DependsOn) > 0 {
+			hostDeps[host.Key()] = host.DependsOn
+		}
+	}
+
+	c.configMu.Lock()
+	c.policy = policy
+	c.hosts = hosts
+	c.hostDeps = hostDeps
+	c.configMu.Unlock()
+}
+
+// filterSilenced 去掉命中生效中静默规则的记录
+// 被静默的记录保持未发送，静默结束后仍未恢复的告警会照常发送，静默期间已恢复的告警不再发送恢复通知
+func (c *Consumer) filterSilenced(alerts []*db.AlertStatus, now time.Time) []*db.AlertStatus {
+	if c.silences == nil || len(alerts) == 0 {
+		return alerts
+	}
+	active, err := c.silences.ActiveSilences(now)
+	if err != nil {
+		c.logError("Failed to fetch active silences", err)
+		return alerts
+	}
+	if len(active) == 0 {
+		return alerts
+	}
+
+	c.configMu.RLock()
+	hosts := c.hosts
+	c.configMu.RUnlock()
+
+	kept := alerts[:0]
+	for _, alert := range alerts {
+		host := hosts[alert.Host]
+		silenced := false
+		for i := range active {
+			// 主机通配符同时匹配检测标识和主机地址，如 10.0.0.* 也匹配 tcp://10.0.0.1:22
+			if active[i].Matches(alert.Host, host.Tags) || (host.Host != "" && active[i].Matches(host.Host, nil)) {
+				c.logger.Log(fmt.Sprintf("Notification for host %s silenced by %s", alert.Host, active[i].ID), "debug")
+				silenced = true
+				break
+			}
+		}
+		if !silenced {
+			kept = append(kept, alert)
+		}
+	}
+	return kept
+}
+
+func (c *Consumer) getPolicy() *RenotifyPolicy {
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
+	return c.policy
+}
+
+// processRenotify 处理已通知但仍未恢复的告警：
+// 持续时间达到新的升级级别时通知该级别的通知器，否则按间隔向默认和已升级的通知器重复通知
+func (c *Consumer) processRenotify(now time.Time) {
+	policy := c.getPolicy()
+	if !policy.Enabled() {
+		return
+	}
+
+	alerts, err := c.db.GetNotifiedStatuses(db.StatusAlert)
+	if err != nil {
+		c.logError("Failed to fetch notified alerts", err)
+		return
+	}
+	alerts = c.filterSilenced(alerts, now)
+	if len(alerts) == 0 {
+		return
+	}
+	children := c.suppressedChildren()
+
+	for _, alert := range alerts {
+		if alert.SuppressedBy != "" {
+			continue // 上游主机故障导致的告警不重复通知
+		}
+		failTime, err := time.Parse(time.RFC3339, alert.FailTime)
+		if err != nil {
+			continue
+		}
+		down := now.Sub(failTime)
+		level := policy.LevelFor(down)
+
+		notice := *alert
+		notice.FlapStopped = false // 重复通知按告警模板发送
+		notice.Dependents = children[alert.Host]
+		notice.Detail = fmt.Sprintf("%s (still failing after %v)", alert.Detail, down.Truncate(time.Minute))
+
+		switch {
+		case level > alert.EscalationLevel:
+			c.logger.Log(fmt.Sprintf("Escalating alert of host %s to level %d", alert.Host, level), "info")
+			policy.notifyLevels(&notice, false, alert.EscalationLevel, level, c.logger)
+		case policy.RenotifyDue(alert, now):
+			c.logger.Log(fmt.Sprintf("Re-notifying alert of host %s", alert.Host), "debug")
+			policy.notifyDefault(&notice, c.logger)
+			policy.notifyLevels(&notice, false, 0, level, c.logger)
+		default:
+			continue
+		}
+
+		// 只记录 renotify/escalation，不经过聚合器，避免再记录一次 alert 通知
+		if err := c.db.MarkNotified(alert.Host, level); err != nil {
+			c.logError(fmt.Sprintf("Failed to update notify state for host %s", alert.Host), err)
+		}
 	}
 }
 
@@ -46,6 +178,7 @@ func (c *Consumer) processEvents(statusType db.StatusType, eventType string) {
 		c.logError(fmt.Sprintf("Failed to fetch unsent %s", eventType), err)
 		return
 	}
+	alerts = c.filterSilenced(alerts, time.Now())
 
 	if len(alerts) == 0 {
 		c.logger.Log(fmt.Sprintf("No unsent %s to process", eventType), "debug")
@@ -54,18 +187,65 @@ func (c *Consumer) processEvents(statusType db.StatusType, eventType string) {
 
 	switch eventType {
 	case "alerts":
+		alerts, suppressed := c.suppressDependents(alerts)
+		if len(alerts) > 0 {
+			if err := c.handler.ProcessAlerts(alerts, c.db); err != nil {
+				c.logError("Failed to process alerts", err)
+				return // 下游主机保持未发送，下一轮重新归并
+			}
+		}
+		for host, root := range suppressed {
+			if err := c.db.MarkSuppressed(host, root); err != nil {
+				c.logError(fmt.Sprintf("Failed to mark host %s as suppressed", host), err)
+			}
+		}
+	case "degradations":
+		// 降级通知与告警走同一流程，由通知器根据状态选择模板
 		if err := c.handler.ProcessAlerts(alerts, c.db); err != nil {
-			c.logError("Failed to process alerts", err)
+			c.logError("Failed to process degradations", err)
+		}
+	case "flapping":
+		// 抖动开始/结束只发送一条通知，由通知器识别抖动记录选择模板
+		if err := c.handler.ProcessAlerts(alerts, c.db); err != nil {
+			c.logError("Failed to process flapping notices", err)
 		}
 	case "recoveries":
+		alerts = c.processSuppressedRecoveries(alerts)
+		alerts = c.dropUnannouncedRecoveries(alerts)
+		if len(alerts) == 0 {
+			return
+		}
 		if err := c.handler.ProcessRecoveries(alerts, c.db); err != nil {
 			c.logError("Failed to process recoveries", err)
+			return // 恢复记录保持未发送，下一轮重试时再通知升级过的通知器，避免重复发送
+		}
+		// 已升级的告警恢复时同时通知升级过的通知器
+		if policy := c.getPolicy(); policy != nil {
+			for _, alert := range alerts {
+				policy.notifyLevels(alert, true, 0, alert.EscalationLevel, c.logger)
+			}
 		}
 	default:
 		c.logger.Log(fmt.Sprintf("Unknown event type: %s", eventType), "warn")
 	}
 }
 
+// dropUnannouncedRecoveries 故障期间从未发送过通知（如一直被静默）时不发送恢复通知，直接标记为已处理
+func (c *Consumer) dropUnannouncedRecoveries(recoveries []*db.AlertStatus) []*db.AlertStatus {
+	kept := recoveries[:0]
+	for _, recovery := range recoveries {
+		if !recovery.Unannounced {
+			kept = append(kept, recovery)
+			continue
+		}
+		c.logger.Log(fmt.Sprintf("Host %s recovered before its alert was sent, skipping recovery notification", recovery.Host), "debug")
+		if err := c.db.MarkHandled(recovery.Host); err != nil {
+			c.logError(fmt.Sprintf("Failed to update status for host %s", recovery.Host), err)
+		}
+	}
+	return kept
+}
+
 // logError 记录错误日志
 func (c *Consumer) logError(message string, err error) {
 	c.logger.Log(fmt.Sprintf("%s: %v", message, err), "error")
@@ -0,0 +1,82 @@
+package notifier
+
+import (
+	"easy-check/internal/config"
+	"easy-check/internal/db"
+	"easy-check/internal/logger"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// newTestLogger 创建只输出错误日志、文件写入临时目录的测试日志
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	return logger.NewLogger(logger.Config{
+		File:         filepath.Join(t.TempDir(), "test.log"),
+		ConsoleLevel: "error",
+		FileLevel:    "error",
+	})
+}
+
+// newTestManagers 创建基于内存数据库的告警状态和静默规则管理器
+func newTestManagers(t *testing.T) (*db.AlertStatusManager, *db.SilenceManager, *logger.Logger) {
+	t.Helper()
+	instance, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { instance.Close() })
+	log := newTestLogger(t)
+	manager, err := db.NewAlertStatusManager(instance, log, config.DbConfig{Expire: 3600})
+	if err != nil {
+		t.Fatal(err)
+	}
+	silences, err := db.NewSilenceManager(instance, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return manager, silences, log
+}
+
+func TestSilencedAlertRecoveredWithinWindow(t *testing.T) {
+	manager, silences, log := newTestManagers(t)
+	handler := &recordingHandler{}
+	c := NewConsumer(manager, silences, log, time.Second, handler)
+	c.UpdateConfig(&config.Config{Hosts: []config.Host{{Host: "a"}}})
+
+	now := time.Now()
+	silence, err := silences.SaveSilence(db.Silence{
+		Hosts:    []string{"a"},
+		StartsAt: now.Add(-time.Minute).Format(time.RFC3339),
+		EndsAt:   now.Add(time.Hour).Format(time.RFC3339),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager.RecordFailure(db.AlertStatus{Host: "a", Status: db.StatusAlert, FailTime: now.Format(time.RFC3339)}, 1)
+	c.processEvents(db.StatusAlert, "alerts")
+	manager.RecordSuccess(db.AlertStatus{Host: "a", Status: db.StatusRecovery, RecoveryTime: now.Format(time.RFC3339)}, 1)
+	c.processEvents(db.StatusRecovery, "recoveries")
+
+	// 静默结束后不再补发从未告警过的恢复通知
+	if err := silences.DeleteSilence(silence.ID); err != nil {
+		t.Fatal(err)
+	}
+	c.processEvents(db.StatusAlert, "alerts")
+	c.processEvents(db.StatusRecovery, "recoveries")
+
+	if len(handler.alerts) != 0 || len(handler.recoveries) != 0 {
+		t.Fatalf("got %d alerts and %d recoveries, want none", len(handler.alerts), len(handler.recoveries))
+	}
+	got, err := manager.GetAlertStatus("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != db.StatusRecovery || !got.Sent {
+		t.Errorf("got status=%s sent=%v, want handled RECOVERY", got.Status, got.Sent)
+	}
+}
@@ -0,0 +1,129 @@
+package notifier
+
+import (
+	"easy-check/internal/db"
+	"fmt"
+)
+
+// suppressDependents 上游主机仍在告警时，将下游主机的告警归并到根因主机：
+// 下游主机不单独通知，根因主机在本批次中时在其通知中注明受影响的下游主机
+// 返回需要发送的告警和被抑制的下游主机（主机 -> 根因主机）
+func (c *Consumer) suppressDependents(alerts []*db.AlertStatus) ([]*db.AlertStatus, map[string]string) {
+	c.configMu.RLock()
+	deps := c.hostDeps
+	c.configMu.RUnlock()
+	if len(deps) == 0 {
+		return alerts, nil
+	}
+
+	down := make(map[string]bool) // 本批次内缓存上游主机是否处于告警
+	suppressed := make(map[string]string)
+	byHost := make(map[string]*db.AlertStatus, len(alerts))
+	for _, alert := range alerts {
+		byHost[alert.Host] = alert
+	}
+
+	kept := make([]*db.AlertStatus, 0, len(alerts))
+	for _, alert := range alerts {
+		root := c.rootCause(alert.Host, deps, down, map[string]bool{alert.Host: true})
+		if root == "" {
+			kept = append(kept, alert)
+			continue
+		}
+		suppressed[alert.Host] = root
+		if parent, ok := byHost[root]; ok {
+			parent.Dependents = append(parent.Dependents, alert.Host)
+		}
+		c.logger.Log(fmt.Sprintf("Alert of host %s suppressed, parent %s is down", alert.Host, root), "debug")
+	}
+
+	// 之前批次中已被抑制的下游主机同样计入受影响数量
+	if len(kept) > 0 {
+		children := c.suppressedChildren()
+		for _, alert := range kept {
+			alert.Dependents = append(children[alert.Host], alert.Dependents...)
+		}
+	}
+	return kept, suppressed
+}
+
+// suppressedChildren 按数据库中的抑制记录返回各上游主机下仍在告警的下游主机
+func (c *Consumer) suppressedChildren() map[string][]string {
+	statuses, err := c.db.GetSuppressedStatuses()
+	if err != nil {
+		c.logError("Failed to fetch suppressed alerts", err)
+		return nil
+	}
+	children := make(map[string][]string)
+	for _, status := range statuses {
+		children[status.SuppressedBy] = append(children[status.SuppressedBy], status.Host)
+	}
+	return children
+}
+
+// rootCause 沿 depends_on 向上查找仍在告警的最上游主机，没有时返回空
+func (c *Consumer) rootCause(host string, deps map[string][]string, down map[string]bool, visited map[string]bool) string {
+	for _, parent := range deps[host] {
+		if visited[parent] {
+			continue // 忽略循环依赖
+		}
+		visited[parent] = true
+		if !c.isDown(parent, down) {
+			continue
+		}
+		if root := c.rootCause(parent, deps, down, visited); root != "" {
+			return root
+		}
+		return parent
+	}
+	return ""
+}
+
+// isDown 判断主机是否处于告警（含已确认、抖动中实际为告警）
+func (c *Consumer) isDown(host string, cache map[string]bool) bool {
+	if isDown, ok := cache[host]; ok {
+		return isDown
+	}
+	status, err := c.db.GetAlertStatus(host)
+	isDown := err == nil && status.State() == db.StatusAlert
+	cache[host] = isDown
+	return isDown
+}
+
+// releaseSuppressed 按数据库中上游主机的状态解除抑制：上游不再告警时（已恢复、记录过期或恢复通知被静默），
+// 仍故障的下游主机重新告警
+func (c *Consumer) releaseSuppressed() {
+	statuses, err := c.db.GetSuppressedStatuses()
+	if err != nil {
+		c.logError("Failed to fetch suppressed alerts", err)
+		return
+	}
+
+	down := make(map[string]bool)
+	released := make(map[string]bool)
+	for _, status := range statuses {
+		parent := status.SuppressedBy
+		if released[parent] || c.isDown(parent, down) {
+			continue
+		}
+		released[parent] = true
+		if err := c.db.ReleaseSuppressed(parent); err != nil {
+			c.logError(fmt.Sprintf("Failed to release dependents of host %s", parent), err)
+		}
+	}
+}
+
+// processSuppressedRecoveries 被抑制期间恢复的下游主机不单独通知，返回需要发送的恢复通知
+func (c *Consumer) processSuppressedRecoveries(recoveries []*db.AlertStatus) []*db.AlertStatus {
+	kept := recoveries[:0]
+	for _, recovery := range recoveries {
+		if recovery.SuppressedBy == "" {
+			kept = append(kept, recovery)
+			continue
+		}
+		if err := c.db.MarkHandled(recovery.Host); err != nil {
+			c.logError(fmt.Sprintf("Failed to update status for host %s", recovery.Host), err)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,167 @@
+package notifier
+
+import (
+	"easy-check/internal/config"
+	"easy-check/internal/db"
+	"easy-check/internal/types"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// recordingHandler 记录收到的通知并标记为已发送
+type recordingHandler struct {
+	alerts      []*db.AlertStatus
+	recoveries  []*db.AlertStatus
+	recoveryErr error // 不为 nil 时恢复通知发送失败
+}
+
+func (h *recordingHandler) ProcessAlerts(alerts []*db.AlertStatus, dbManager *db.AlertStatusManager) error {
+	h.alerts = append(h.alerts, alerts...)
+	for _, alert := range alerts {
+		dbManager.UpdateSentStatus(alert.Host, true)
+	}
+	return nil
+}
+
+func (h *recordingHandler) ProcessRecoveries(recoveries []*db.AlertStatus, dbManager *db.AlertStatusManager) error {
+	if h.recoveryErr != nil {
+		return h.recoveryErr
+	}
+	h.recoveries = append(h.recoveries, recoveries...)
+	for _, recovery := range recoveries {
+		dbManager.UpdateSentStatus(recovery.Host, true)
+	}
+	return nil
+}
+
+func TestSuppressDependents(t *testing.T) {
+	instance, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer instance.Close()
+	log := newTestLogger(t)
+	manager, err := db.NewAlertStatusManager(instance, log, config.DbConfig{Expire: 3600})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := &recordingHandler{}
+	c := NewConsumer(manager, nil, log, time.Second, handler)
+	c.UpdateConfig(&config.Config{Hosts: []config.Host{
+		{Host: "router"},
+		{Host: "switch", DependsOn: []string{"router"}},
+		{Host: "server-1", DependsOn: []string{"switch"}},
+		{Host: "server-2", DependsOn: []string{"switch"}},
+	}})
+
+	fail := func(host string) {
+		manager.RecordFailure(db.AlertStatus{Host: host, Status: db.StatusAlert, FailTime: time.Now().Format(time.RFC3339)}, 2)
+		manager.RecordFailure(db.AlertStatus{Host: host, Status: db.StatusAlert, FailTime: time.Now().Format(time.RFC3339)}, 2)
+	}
+	recoverHost := func(host string) {
+		manager.RecordSuccess(db.AlertStatus{Host: host, Status: db.StatusRecovery, RecoveryTime: time.Now().Format(time.RFC3339)}, 1)
+	}
+	for _, host := range []string{"router", "switch", "server-1", "server-2"} {
+		fail(host)
+	}
+
+	// 只发送根因主机的告警，下游主机计入受影响数量
+	c.processEvents(db.StatusAlert, "alerts")
+	if len(handler.alerts) != 1 || handler.alerts[0].Host != "router" || len(handler.alerts[0].Dependents) != 3 {
+		t.Fatalf("got %d alerts, want only router with 3 dependents", len(handler.alerts))
+	}
+	got, _ := manager.GetAlertStatus("server-1")
+	if got.SuppressedBy != "router" || got.Reason != db.ReasonParentDown || !got.Sent {
+		t.Errorf("server-1: got suppressed_by=%q reason=%s sent=%v", got.SuppressedBy, got.Reason, got.Sent)
+	}
+
+	// 上游恢复后，被抑制过的下游恢复时不单独通知，仍故障的下游重新告警
+	recoverHost("router")
+	recoverHost("switch")
+	recoverHost("server-1")
+	handler.alerts = nil
+	c.releaseSuppressed()
+	c.processEvents(db.StatusRecovery, "recoveries")
+	c.processEvents(db.StatusAlert, "alerts")
+
+	var recovered []string
+	for _, r := range handler.recoveries {
+		recovered = append(recovered, r.Host)
+	}
+	if len(recovered) != 1 || recovered[0] != "router" {
+		t.Errorf("got recoveries %v, want router only", recovered)
+	}
+	if len(handler.alerts) != 1 || handler.alerts[0].Host != "server-2" {
+		t.Errorf("got %d alerts after parent recovery, want server-2 only", len(handler.alerts))
+	}
+}
+
+func TestSuppressedDependentRecoversAfterRelease(t *testing.T) {
+	manager, silences, log := newTestManagers(t)
+	handler := &recordingHandler{}
+	c := NewConsumer(manager, silences, log, time.Second, handler)
+	c.UpdateConfig(&config.Config{Hosts: []config.Host{
+		{Host: "router"},
+		{Host: "server", DependsOn: []string{"router"}},
+	}})
+
+	now := time.Now().Format(time.RFC3339)
+	for _, host := range []string{"router", "server"} {
+		manager.RecordFailure(db.AlertStatus{Host: host, Status: db.StatusAlert, FailTime: now}, 1)
+	}
+	c.processEvents(db.StatusAlert, "alerts")
+
+	// 上游恢复通知被静默时，按数据库中上游的状态解除抑制
+	if _, err := silences.SaveSilence(db.Silence{Hosts: []string{"router"}, StartsAt: now, EndsAt: time.Now().Add(time.Hour).Format(time.RFC3339)}); err != nil {
+		t.Fatal(err)
+	}
+	manager.RecordSuccess(db.AlertStatus{Host: "router", Status: db.StatusRecovery, RecoveryTime: now}, 1)
+	c.processEvents(db.StatusRecovery, "recoveries")
+	c.releaseSuppressed()
+	got, _ := manager.GetAlertStatus("server")
+	if got.SuppressedBy != "" || got.Sent {
+		t.Fatalf("server: got suppressed_by=%q sent=%v, want released and pending", got.SuppressedBy, got.Sent)
+	}
+
+	// 重新告警发送前已恢复，不发送恢复通知
+	manager.RecordSuccess(db.AlertStatus{Host: "server", Status: db.StatusRecovery, RecoveryTime: now}, 1)
+	c.processEvents(db.StatusRecovery, "recoveries")
+	if len(handler.alerts) != 1 || handler.alerts[0].Host != "router" || len(handler.recoveries) != 0 {
+		t.Errorf("got %d alerts and %d recoveries, want only the router alert", len(handler.alerts), len(handler.recoveries))
+	}
+	if got, _ := manager.GetAlertStatus("server"); !got.Sent {
+		t.Error("server recovery should be marked as handled")
+	}
+}
+
+func TestDependentsCountIncludesEarlierSuppressions(t *testing.T) {
+	manager, _, log := newTestManagers(t)
+	handler := &recordingHandler{}
+	reminder := &recordingNotifier{}
+	c := NewConsumer(manager, nil, log, time.Second, handler)
+	c.UpdateConfig(&config.Config{Hosts: []config.Host{
+		{Host: "router"},
+		{Host: "server-1", DependsOn: []string{"router"}},
+		{Host: "server-2", DependsOn: []string{"router"}},
+	}})
+	c.policy = &RenotifyPolicy{Interval: 30 * time.Minute, Default: []types.Notifier{reminder}}
+
+	now := time.Now()
+	failTime := now.Add(-time.Hour).Format(time.RFC3339)
+	// 下游主机在上游告警发送之后的不同轮次中依次故障
+	for _, host := range []string{"router", "server-1", "server-2"} {
+		manager.RecordFailure(db.AlertStatus{Host: host, Status: db.StatusAlert, FailTime: failTime}, 1)
+		c.processEvents(db.StatusAlert, "alerts")
+	}
+	if len(handler.alerts) != 1 || handler.alerts[0].Host != "router" {
+		t.Fatalf("got %d alerts, want only router", len(handler.alerts))
+	}
+
+	c.processRenotify(now.Add(time.Hour))
+	if len(reminder.alerts) != 1 || len(reminder.alerts[0].Dependents) != 2 {
+		t.Fatalf("got %d re-notifications, want router with 2 dependents", len(reminder.alerts))
+	}
+}
@@ -0,0 +1,210 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"easy-check/internal/db"
+	"easy-check/internal/logger"
+	"easy-check/internal/types"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DingTalkNotifier 钉钉群机器人通知器，标题和内容模板与飞书通知器使用相同的配置项
+type DingTalkNotifier struct {
+	WebhookURL string
+	MsgType    string // text 或 markdown
+	Secret     string // 加签密钥，为空时不签名
+	AtMobiles  []string
+	AtUserIDs  []string
+	AtAll      bool
+	Logger     *logger.Logger
+	Options    map[string]interface{}
+}
+
+// DingTalkMessage 钉钉机器人消息结构
+type DingTalkMessage struct {
+	MsgType  string            `json:"msgtype"`
+	Text     *DingTalkText     `json:"text,omitempty"`
+	Markdown *DingTalkMarkdown `json:"markdown,omitempty"`
+	At       DingTalkAt        `json:"at"`
+}
+
+type DingTalkText struct {
+	Content string `json:"content"`
+}
+
+type DingTalkMarkdown struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// DingTalkAt 消息中 @ 的成员
+type DingTalkAt struct {
+	AtMobiles []string `json:"atMobiles,omitempty"`
+	AtUserIDs []string `json:"atUserIds,omitempty"`
+	IsAtAll   bool     `json:"isAtAll"`
+}
+
+// DingTalkResponse 钉钉 API 响应结构
+type DingTalkResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// NewDingTalkNotifier 创建钉钉通知器
+func NewDingTalkNotifier(options map[string]interface{}, logger *logger.Logger) (types.Notifier, error) {
+	webhookURL, ok := options["webhook"].(string)
+	if !ok || webhookURL == "" {
+		return nil, fmt.Errorf("missing webhook URL in DingTalk notifier options")
+	}
+
+	msgType := optionString(options, "msg_type", "text")
+	if msgType != "text" && msgType != "markdown" {
+		return nil, fmt.Errorf("unsupported message type %q in DingTalk notifier options", msgType)
+	}
+
+	atAll, _ := options["at_all"].(bool)
+	return &DingTalkNotifier{
+		WebhookURL: webhookURL,
+		MsgType:    msgType,
+		Secret:     optionString(options, "secret", ""),
+		AtMobiles:  optionStrings(options, "at_mobiles"),
+		AtUserIDs:  optionStrings(options, "at_user_ids"),
+		AtAll:      atAll,
+		Logger:     logger,
+		Options:    options,
+	}, nil
+}
+
+// SendNotification 发送单个主机的告警/恢复通知
+func (d *DingTalkNotifier) SendNotification(alert *db.AlertStatus, isRecovery bool) error {
+	title, content, err := renderNotification(d.Options, alert, isRecovery)
+	if err != nil {
+		d.Logger.Log(fmt.Sprintf("Error rendering notification: %v", err), "error")
+		return err
+	}
+	if err := d.sendMessage(title, content); err != nil {
+		return fmt.Errorf("failed to send notification: %v", err)
+	}
+	d.Logger.Log("Successfully sent notification via DingTalk", "debug")
+	return nil
+}
+
+// SendAggregatedNotification 发送聚合通知（告警或恢复）
+func (d *DingTalkNotifier) SendAggregatedNotification(alerts []*db.AlertStatus, isRecovery bool) error {
+	title, content, err := renderAggregatedNotification(d.Options, alerts, isRecovery)
+	if err != nil {
+		d.Logger.Log(fmt.Sprintf("Error preparing aggregated content: %v", err), "error")
+		return err
+	}
+	if err := d.sendMessage(title, content); err != nil {
+		return err
+	}
+	d.Logger.Log("Successfully sent aggregated notification via DingTalk", "debug")
+	return nil
+}
+
+// SendReport 发送报告消息，如定期可用性报告
+func (d *DingTalkNotifier) SendReport(title, content string) error {
+	if err := d.sendMessage(title, content); err != nil {
+		return fmt.Errorf("failed to send report: %v", err)
+	}
+	d.Logger.Log("Successfully sent report via DingTalk", "debug")
+	return nil
+}
+
+func (d *DingTalkNotifier) Close() error {
+	d.Logger.Log("Closing DingTalkNotifier", "debug")
+	return nil
+}
+
+// buildMessage 根据消息类型组装标题、内容和 @ 成员
+func (d *DingTalkNotifier) buildMessage(title, content string) DingTalkMessage {
+	message := DingTalkMessage{
+		MsgType: d.MsgType,
+		At: DingTalkAt{
+			AtMobiles: d.AtMobiles,
+			AtUserIDs: d.AtUserIDs,
+			IsAtAll:   d.AtAll,
+		},
+	}
+	if d.MsgType != "markdown" {
+		message.Text = &DingTalkText{Content: fmt.Sprintf("%s\n%s", title, content)}
+		return message
+	}
+
+	// markdown 中单个换行不会换行，且 @ 的成员需要出现在正文中才会高亮
+	text := fmt.Sprintf("#### %s\n\n%s", title, strings.ReplaceAll(content, "\n", "\n\n"))
+	var mentions []string
+	for _, mobile := range d.AtMobiles {
+		mentions = append(mentions, "@"+mobile)
+	}
+	for _, userID := range d.AtUserIDs {
+		mentions = append(mentions, "@"+userID)
+	}
+	if len(mentions) > 0 {
+		text += "\n\n" + strings.Join(mentions, " ")
+	}
+	message.Markdown = &DingTalkMarkdown{Title: title, Text: text}
+	return message
+}
+
+// signedURL 配置了密钥时在 webhook 后追加 timestamp 和 sign 参数
+// sign 为 "timestamp\nsecret" 以 secret 为密钥的 HmacSHA256 值，再做 Base64 编码
+func (d *DingTalkNotifier) signedURL(now time.Time) (string, error) {
+	if d.Secret == "" {
+		return d.WebhookURL, nil
+	}
+	u, err := url.Parse(d.WebhookURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid webhook URL: %v", err)
+	}
+
+	timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(d.Secret))
+	mac.Write([]byte(timestamp + "\n" + d.Secret))
+
+	query := u.Query()
+	query.Set("timestamp", timestamp)
+	query.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// sendMessage 发送消息
+func (d *DingTalkNotifier) sendMessage(title, content string) error {
+	d.Logger.Log(fmt.Sprintf("Sending DingTalk message: %s\n%s", title, content), "debug")
+
+	data, err := json.Marshal(d.buildMessage(title, content))
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %v", err)
+	}
+
+	webhookURL, err := d.signedURL(time.Now())
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Post(webhookURL, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var dingTalkResp DingTalkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dingTalkResp); err != nil {
+		return fmt.Errorf("failed to parse response: %v", err)
+	}
+	if dingTalkResp.ErrCode != 0 {
+		return fmt.Errorf("API error: code=%d, message=%s", dingTalkResp.ErrCode, dingTalkResp.ErrMsg)
+	}
+	return nil
+}
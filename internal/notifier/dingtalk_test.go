@@ -0,0 +1,66 @@
+package notifier
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"easy-check/internal/db"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDingTalkNotifier(t *testing.T) {
+	const secret = "SEC-test"
+	var received DingTalkMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timestamp := r.URL.Query().Get("timestamp")
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "\n" + secret))
+		if r.URL.Query().Get("sign") != base64.StdEncoding.EncodeToString(mac.Sum(nil)) {
+			json.NewEncoder(w).Encode(DingTalkResponse{ErrCode: 310000, ErrMsg: "sign not match"})
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+		json.NewEncoder(w).Encode(DingTalkResponse{ErrMsg: "ok"})
+	}))
+	defer server.Close()
+
+	log := newTestLogger(t)
+	n, err := NewDingTalkNotifier(map[string]interface{}{
+		"webhook":             server.URL + "/robot/send?access_token=token",
+		"msg_type":            "markdown",
+		"secret":              secret,
+		"at_mobiles":          []interface{}{13800000000},
+		"alert_line_template": "- {{.Host}}",
+	}, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alerts := []*db.AlertStatus{{Host: "10.0.0.1", Status: db.StatusAlert}, {Host: "10.0.0.2", Status: db.StatusAlert}}
+	if err := n.SendAggregatedNotification(alerts, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if received.MsgType != "markdown" || received.Markdown == nil {
+		t.Fatalf("expected markdown message, got %+v", received)
+	}
+	text := received.Markdown.Text
+	if !strings.Contains(text, "- 10.0.0.1\n\n- 10.0.0.2") || !strings.HasSuffix(text, "@13800000000") {
+		t.Fatalf("unexpected markdown text: %q", text)
+	}
+	if len(received.At.AtMobiles) != 1 || received.At.AtMobiles[0] != "13800000000" {
+		t.Fatalf("unexpected mentions: %+v", received.At)
+	}
+
+	// 密钥错误时返回 API 错误
+	n.(*DingTalkNotifier).Secret = "wrong"
+	if err := n.SendReport("report", "content"); err == nil {
+		t.Fatal("expected sign error")
+	}
+}
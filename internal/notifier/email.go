@@ -0,0 +1,314 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/tls"
+	"easy-check/internal/db"
+	"easy-check/internal/logger"
+	"easy-check/internal/types"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 邮件连接的加密方式
+const (
+	EmailTLSNone     = "none"     // 不加密
+	EmailTLSStartTLS = "starttls" // 明文连接后通过 STARTTLS 升级，默认端口 587
+	EmailTLSImplicit = "tls"      // 连接即使用 TLS（SMTPS），默认端口 465
+)
+
+const emailTimeout = 30 * time.Second
+
+// defaultEmailBody 默认的 HTML 正文模板，单个主机和聚合通知共用
+const defaultEmailBody = `<html><body style="font-family:sans-serif">
+<h3 style="color:{{if .IsRecovery}}#2e7d32{{else}}#c62828{{end}}">{{.Title | html}}</h3>
+<p>发送时间：{{.Date}} {{.Time}}，共 {{.AlertCount}} 个主机</p>
+<table border="1" cellspacing="0" cellpadding="6" style="border-collapse:collapse">
+<tr><th>主机</th><th>描述</th><th>开始时间</th>{{if .IsRecovery}}<th>恢复时间</th>{{else}}<th>原因</th>{{end}}</tr>
+{{range .Hosts}}<tr><td>{{.Host | html}}</td><td>{{.Description | html}}</td><td>{{.FailTime}}</td>{{if $.IsRecovery}}<td>{{.RecoveryTime}}{{.AckNote | html}}</td>{{else}}<td>{{.Detail | html}}{{.DependentNote | html}}</td>{{end}}</tr>
+{{end}}</table>
+</body></html>`
+
+// EmailTemplateData 邮件主题和正文模板的数据，在 TemplateData 的基础上增加标题和格式化后的主机信息
+// 模板使用 text/template 渲染，正文中的变量需要通过 {{.Host | html}} 等方式转义
+type EmailTemplateData struct {
+	TemplateData
+	Title      string              // 与其他通知器相同的标题，如 alert_title
+	IsRecovery bool                // 是否为恢复通知
+	Hosts      []alertTemplateData // 每个主机格式化后的信息，字段与 *_line_template 中可用的变量相同
+}
+
+// EmailNotifier SMTP 邮件通知器
+type EmailNotifier struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	From       string
+	To         []string
+	TLS        string // none、starttls（默认）、tls
+	Auth       string // plain（配置了用户名时默认）、login、none
+	SkipVerify bool   // 跳过服务器证书校验
+	Logger     *logger.Logger
+	Options    map[string]interface{}
+}
+
+// NewEmailNotifier 创建邮件通知器
+func NewEmailNotifier(options map[string]interface{}, logger *logger.Logger) (types.Notifier, error) {
+	e := &EmailNotifier{
+		Host:     optionString(options, "host", ""),
+		Username: optionString(options, "username", ""),
+		Password: optionString(options, "password", ""),
+		From:     optionString(options, "from", ""),
+		To:       optionStrings(options, "to"),
+		TLS:      optionString(options, "tls", EmailTLSStartTLS),
+		Logger:   logger,
+		Options:  options,
+	}
+	e.SkipVerify, _ = options["skip_verify"].(bool)
+
+	if e.Host == "" {
+		return nil, fmt.Errorf("missing host in email notifier options")
+	}
+	if len(e.To) == 0 {
+		return nil, fmt.Errorf("missing recipients in email notifier options")
+	}
+	if e.From == "" {
+		e.From = e.Username
+	}
+	if e.From == "" {
+		return nil, fmt.Errorf("missing from address in email notifier options")
+	}
+
+	switch e.TLS {
+	case EmailTLSNone:
+		e.Port = 25
+	case EmailTLSStartTLS:
+		e.Port = 587
+	case EmailTLSImplicit:
+		e.Port = 465
+	default:
+		return nil, fmt.Errorf("unsupported tls mode %q in email notifier options", e.TLS)
+	}
+	if port, ok := options["port"].(int); ok && port > 0 {
+		e.Port = port
+	}
+
+	defaultAuth := "none"
+	if e.Username != "" {
+		defaultAuth = "plain"
+	}
+	e.Auth = optionString(options, "auth", defaultAuth)
+	if e.Auth != "none" && e.Auth != "plain" && e.Auth != "login" {
+		return nil, fmt.Errorf("unsupported auth %q in email notifier options", e.Auth)
+	}
+
+	return e, nil
+}
+
+// SendNotification 发送单个主机的告警/恢复通知，使用 alert_* 或 recovery_* 模板
+func (e *EmailNotifier) SendNotification(alert *db.AlertStatus, isRecovery bool) error {
+	prefix := "alert"
+	if isRecovery {
+		prefix = "recovery"
+	}
+	if err := e.sendAlerts(prefix, []*db.AlertStatus{alert}, isRecovery); err != nil {
+		return fmt.Errorf("failed to send notification: %v", err)
+	}
+	e.Logger.Log("Successfully sent notification via email", "debug")
+	return nil
+}
+
+// SendAggregatedNotification 发送聚合通知（告警或恢复），使用 aggregate_* 模板
+func (e *EmailNotifier) SendAggregatedNotification(alerts []*db.AlertStatus, isRecovery bool) error {
+	if len(alerts) == 0 {
+		return fmt.Errorf("no alerts to process")
+	}
+	if err := e.sendAlerts("aggregate", alerts, isRecovery); err != nil {
+		return err
+	}
+	e.Logger.Log(fmt.Sprintf("Successfully sent aggregated notification via email to %d recipient(s)", len(e.To)), "debug")
+	return nil
+}
+
+// SendReport 发送报告邮件，内容按原样放在 <pre> 中
+func (e *EmailNotifier) SendReport(title, content string) error {
+	body := fmt.Sprintf("<html><body><h3>%s</h3><pre>%s</pre></body></html>", html.EscapeString(title), html.EscapeString(content))
+	if err := e.send(title, body); err != nil {
+		return fmt.Errorf("failed to send report: %v", err)
+	}
+	e.Logger.Log("Successfully sent report via email", "debug")
+	return nil
+}
+
+func (e *EmailNotifier) Close() error {
+	e.Logger.Log("Closing EmailNotifier", "debug")
+	return nil
+}
+
+// sendAlerts 使用 <prefix>_subject 和 <prefix>_body 模板渲染并发送邮件，主题默认为通知标题
+func (e *EmailNotifier) sendAlerts(prefix string, alerts []*db.AlertStatus, isRecovery bool) error {
+	lines, err := renderAlertList(e.Options, alerts, isRecovery)
+	if err != nil {
+		return err
+	}
+
+	data := EmailTemplateData{
+		TemplateData: TemplateData{
+			Date:       time.Now().Format("2006-01-02"),
+			Time:       time.Now().Format("15:04:05"),
+			AlertCount: len(alerts),
+			AlertList:  strings.Join(lines, "\n"),
+			Alerts:     alerts,
+		},
+		Title:      notificationTitle(e.Options, alerts[0], isRecovery),
+		IsRecovery: isRecovery,
+	}
+	for _, alert := range alerts {
+		data.Hosts = append(data.Hosts, newAlertTemplateData(alert))
+	}
+
+	subject, err := renderTemplate("subject", optionString(e.Options, prefix+"_subject", "{{.Title}}"), data)
+	if err != nil {
+		return err
+	}
+	body, err := renderTemplate("body", optionString(e.Options, prefix+"_body", defaultEmailBody), data)
+	if err != nil {
+		return err
+	}
+	return e.send(strings.TrimSpace(subject), body)
+}
+
+// send 连接 SMTP 服务器并向所有收件人发送一封 HTML 邮件
+func (e *EmailNotifier) send(subject, body string) error {
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	tlsConfig := &tls.Config{ServerName: e.Host, InsecureSkipVerify: e.SkipVerify}
+	dialer := &net.Dialer{Timeout: emailTimeout}
+
+	var conn net.Conn
+	var err error
+	if e.TLS == EmailTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %v", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(emailTimeout))
+
+	client, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to create SMTP client: %v", err)
+	}
+	defer client.Close()
+
+	if e.TLS == EmailTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("failed to start TLS: %v", err)
+		}
+	}
+
+	if auth := e.smtpAuth(); auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %v", err)
+		}
+	}
+
+	if err := client.Mail(e.From); err != nil {
+		return fmt.Errorf("MAIL FROM failed: %v", err)
+	}
+	for _, to := range e.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("RCPT TO %s failed: %v", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("DATA failed: %v", err)
+	}
+	if _, err := w.Write(e.buildMessage(subject, body)); err != nil {
+		return fmt.Errorf("failed to write message: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %v", err)
+	}
+	return client.Quit()
+}
+
+func (e *EmailNotifier) smtpAuth() smtp.Auth {
+	switch e.Auth {
+	case "plain":
+		return smtp.PlainAuth("", e.Username, e.Password, e.Host)
+	case "login":
+		return &loginAuth{username: e.Username, password: e.Password, host: e.Host}
+	default:
+		return nil
+	}
+}
+
+// buildMessage 生成邮件头和 base64 编码的 HTML 正文
+func (e *EmailNotifier) buildMessage(subject, body string) []byte {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "From: %s\r\n", e.From)
+	fmt.Fprintf(&buffer, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&buffer, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&buffer, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buffer.WriteString("MIME-Version: 1.0\r\n")
+	buffer.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	buffer.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(body))
+	for len(encoded) > 76 {
+		buffer.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buffer.WriteString(encoded + "\r\n")
+	return buffer.Bytes()
+}
+
+// loginAuth 实现 AUTH LOGIN，与 smtp.PlainAuth 一样只允许在 TLS 连接或本机上发送密码
+type loginAuth struct {
+	username, password, host string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected server challenge: %s", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
@@ -0,0 +1,219 @@
+package notifier
+
+import (
+	"bufio"
+	"crypto/tls"
+	"easy-check/internal/db"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testSMTPServer 进程内的最小 SMTP 服务器，支持 STARTTLS、SMTPS 以及 AUTH PLAIN/LOGIN
+type testSMTPServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	username  string
+	password  string
+
+	mu       sync.Mutex
+	rcpts    []string
+	messages []string
+}
+
+func newTestSMTPServer(t *testing.T, implicitTLS bool) *testSMTPServer {
+	// 借用 httptest 的自签名证书
+	https := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(https.Close)
+
+	s := &testSMTPServer{
+		tlsConfig: &tls.Config{Certificates: https.TLS.Certificates},
+		username:  "bot@example.com",
+		password:  "secret",
+	}
+	var err error
+	if implicitTLS {
+		s.listener, err = tls.Listen("tcp", "127.0.0.1:0", s.tlsConfig)
+	} else {
+		s.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.listener.Close() })
+
+	go func() {
+		for {
+			conn, err := s.listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, implicitTLS)
+		}
+	}()
+	return s
+}
+
+func (s *testSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *testSMTPServer) serve(conn net.Conn, isTLS bool) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(lines ...string) { fmt.Fprint(conn, strings.Join(lines, "\r\n")+"\r\n") }
+	readLine := func() string {
+		line, _ := r.ReadString('\n')
+		return strings.TrimRight(line, "\r\n")
+	}
+	decode := func(s string) string {
+		b, _ := base64.StdEncoding.DecodeString(s)
+		return string(b)
+	}
+	authenticated := false
+
+	reply("220 localhost ESMTP")
+	for {
+		line := readLine()
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch {
+		case cmd == "EHLO":
+			if isTLS {
+				reply("250-localhost", "250 AUTH PLAIN LOGIN")
+			} else {
+				reply("250-localhost", "250-STARTTLS", "250 AUTH PLAIN LOGIN")
+			}
+		case cmd == "STARTTLS":
+			reply("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, r, isTLS = tlsConn, bufio.NewReader(tlsConn), true
+		case strings.HasPrefix(line, "AUTH PLAIN "):
+			parts := strings.Split(decode(strings.TrimPrefix(line, "AUTH PLAIN ")), "\x00")
+			authenticated = len(parts) == 3 && parts[1] == s.username && parts[2] == s.password
+		case line == "AUTH LOGIN":
+			reply("334 " + base64.StdEncoding.EncodeToString([]byte("Username:")))
+			username := decode(readLine())
+			reply("334 " + base64.StdEncoding.EncodeToString([]byte("Password:")))
+			authenticated = username == s.username && decode(readLine()) == s.password
+		case cmd == "MAIL" && !authenticated:
+			reply("530 authentication required")
+		case cmd == "MAIL":
+			reply("250 ok")
+		case cmd == "RCPT":
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+			s.mu.Unlock()
+			reply("250 ok")
+		case cmd == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for l := readLine(); l != "."; l = readLine() {
+				data.WriteString(l + "\r\n")
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		case line == "":
+			return
+		default:
+			reply("250 ok")
+		}
+		// AUTH 的结果统一在此回复
+		if strings.HasPrefix(line, "AUTH ") {
+			if authenticated {
+				reply("235 authenticated")
+			} else {
+				reply("535 authentication failed")
+			}
+		}
+	}
+}
+
+func TestEmailNotifier(t *testing.T) {
+	log := newTestLogger(t)
+
+	tests := []struct {
+		tls, auth string
+	}{
+		{EmailTLSNone, "login"},
+		{EmailTLSStartTLS, "plain"},
+		{EmailTLSImplicit, "login"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tls+"-"+tt.auth, func(t *testing.T) {
+			server := newTestSMTPServer(t, tt.tls == EmailTLSImplicit)
+			n, err := NewEmailNotifier(map[string]interface{}{
+				"host":              "127.0.0.1",
+				"port":              server.port(),
+				"tls":               tt.tls,
+				"auth":              tt.auth,
+				"skip_verify":       true,
+				"username":          server.username,
+				"password":          server.password,
+				"to":                []interface{}{"ops@example.com", "manager@example.com"},
+				"aggregate_subject": "{{.Title}}（{{.AlertCount}} 个主机）",
+			}, log)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			alerts := []*db.AlertStatus{
+				{Host: "10.0.0.1", Description: "网关", Status: db.StatusAlert, Detail: "packet loss <100%>"},
+				{Host: "10.0.0.2", Description: "交换机", Status: db.StatusAlert, Detail: "timeout"},
+			}
+			if err := n.SendAggregatedNotification(alerts, false); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(server.rcpts) != 2 || server.rcpts[1] != "manager@example.com" {
+				t.Fatalf("unexpected recipients: %v", server.rcpts)
+			}
+			msg, err := mail.ReadMessage(strings.NewReader(server.messages[0]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+			if subject != "💔【easy-check】：告警通知（2 个主机）" {
+				t.Fatalf("unexpected subject: %q", subject)
+			}
+			body, _ := io.ReadAll(base64.NewDecoder(base64.StdEncoding, msg.Body))
+			if !strings.Contains(string(body), "<td>10.0.0.2</td>") || !strings.Contains(string(body), "packet loss &lt;100%&gt;") {
+				t.Fatalf("unexpected body: %s", body)
+			}
+		})
+	}
+}
+
+func TestEmailNotifierAuthFailure(t *testing.T) {
+	log := newTestLogger(t)
+	server := newTestSMTPServer(t, false)
+	n, err := NewEmailNotifier(map[string]interface{}{
+		"host":     "127.0.0.1",
+		"port":     server.port(),
+		"tls":      EmailTLSNone,
+		"username": server.username,
+		"password": "wrong",
+		"to":       "ops@example.com",
+	}, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.SendNotification(&db.AlertStatus{Host: "10.0.0.1", Status: db.StatusAlert}, false); err == nil || !strings.Contains(err.Error(), "authenticate") {
+		t.Fatalf("expected authentication error, got %v", err)
+	}
+}
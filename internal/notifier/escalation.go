@@ -0,0 +1,101 @@
+package notifier
+
+import (
+	"easy-check/internal/config"
+	"easy-check/internal/db"
+	"easy-check/internal/logger"
+	"easy-check/internal/types"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// EscalationLevel 告警持续 After 后额外通知的通知器
+type EscalationLevel struct {
+	After     time.Duration
+	Notifiers []types.Notifier
+}
+
+// RenotifyPolicy 未恢复告警的重复通知与升级策略
+type RenotifyPolicy struct {
+	Interval time.Duration     // 重复通知间隔，0 表示不重复
+	Default  []types.Notifier  // 接收重复通知的默认通知器
+	Levels   []EscalationLevel // 按 After 升序排列
+}
+
+// NewRenotifyPolicy 根据配置创建重复通知与升级策略，升级中的通知器按名称创建
+func NewRenotifyPolicy(cfg *config.Config, logger *logger.Logger) *RenotifyPolicy {
+	policy := &RenotifyPolicy{
+		Interval: time.Duration(cfg.Alert.RenotifyInterval) * time.Minute,
+	}
+	if policy.Interval > 0 {
+		policy.Default = CreateNotifiers(cfg, logger)
+	}
+
+	for _, escalation := range cfg.Alert.Escalations {
+		if escalation.After <= 0 {
+			logger.Log(fmt.Sprintf("Escalation to %v has no valid after, skipping", escalation.Notifiers), "warn")
+			continue
+		}
+		policy.Levels = append(policy.Levels, EscalationLevel{
+			After:     time.Duration(escalation.After) * time.Minute,
+			Notifiers: CreateNotifiersByName(cfg, escalation.Notifiers, logger),
+		})
+	}
+	sort.SliceStable(policy.Levels, func(i, j int) bool {
+		return policy.Levels[i].After < policy.Levels[j].After
+	})
+
+	return policy
+}
+
+// Enabled 是否配置了重复通知或升级
+func (p *RenotifyPolicy) Enabled() bool {
+	return p != nil && (p.Interval > 0 || len(p.Levels) > 0)
+}
+
+// LevelFor 返回告警持续 down 后应达到的升级级别
+func (p *RenotifyPolicy) LevelFor(down time.Duration) int {
+	level := 0
+	for _, l := range p.Levels {
+		if down >= l.After {
+			level++
+		}
+	}
+	return level
+}
+
+// RenotifyDue 距离上次通知是否已超过重复通知间隔
+func (p *RenotifyPolicy) RenotifyDue(alert *db.AlertStatus, now time.Time) bool {
+	if p.Interval <= 0 {
+		return false
+	}
+	last, err := time.Parse(time.RFC3339, alert.LastNotifiedAt)
+	if err != nil {
+		// 旧记录没有通知时间时以告警开始时间为准
+		if last, err = time.Parse(time.RFC3339, alert.FailTime); err != nil {
+			return false
+		}
+	}
+	return now.Sub(last) >= p.Interval
+}
+
+// notifyDefault 向默认通知器发送重复通知，不经过聚合器，也不改变记录的发送状态
+func (p *RenotifyPolicy) notifyDefault(alert *db.AlertStatus, logger *logger.Logger) {
+	for _, n := range p.Default {
+		if err := n.SendNotification(alert, false); err != nil {
+			logger.Log(fmt.Sprintf("Failed to send re-notification for host %s: %v", alert.Host, err), "error")
+		}
+	}
+}
+
+// notifyLevels 向 [from, to) 级的通知器发送通知
+func (p *RenotifyPolicy) notifyLevels(alert *db.AlertStatus, isRecovery bool, from, to int, logger *logger.Logger) {
+	for i := from; i < to && i < len(p.Levels); i++ {
+		for _, n := range p.Levels[i].Notifiers {
+			if err := n.SendNotification(alert, isRecovery); err != nil {
+				logger.Log(fmt.Sprintf("Failed to send escalation level %d notification for host %s: %v", i+1, alert.Host, err), "error")
+			}
+		}
+	}
+}
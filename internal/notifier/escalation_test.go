@@ -0,0 +1,131 @@
+package notifier
+
+import (
+	"easy-check/internal/db"
+	"easy-check/internal/types"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenotifyPolicy(t *testing.T) {
+	policy := &RenotifyPolicy{
+		Interval: 30 * time.Minute,
+		Levels: []EscalationLevel{
+			{After: 30 * time.Minute},
+			{After: 2 * time.Hour},
+		},
+	}
+
+	for down, want := range map[time.Duration]int{
+		10 * time.Minute: 0,
+		30 * time.Minute: 1,
+		time.Hour:        1,
+		3 * time.Hour:    2,
+	} {
+		if got := policy.LevelFor(down); got != want {
+			t.Errorf("LevelFor(%v) = %d, want %d", down, got, want)
+		}
+	}
+
+	now := time.Now()
+	alert := &db.AlertStatus{
+		FailTime:       now.Add(-time.Hour).Format(time.RFC3339),
+		LastNotifiedAt: now.Add(-10 * time.Minute).Format(time.RFC3339),
+	}
+	if policy.RenotifyDue(alert, now) {
+		t.Error("re-notification due 10 minutes after the last notice")
+	}
+	if !policy.RenotifyDue(alert, now.Add(20*time.Minute)) {
+		t.Error("re-notification not due 30 minutes after the last notice")
+	}
+
+	// 没有通知时间的旧记录以告警开始时间为准
+	alert.LastNotifiedAt = ""
+	if !policy.RenotifyDue(alert, now) {
+		t.Error("re-notification not due for a record without last_notified_at")
+	}
+
+	if (&RenotifyPolicy{}).Enabled() {
+		t.Error("empty policy should be disabled")
+	}
+}
+
+// recordingNotifier 记录收到的单条通知
+type recordingNotifier struct {
+	alerts []*db.AlertStatus
+}
+
+func (n *recordingNotifier) SendNotification(alert *db.AlertStatus, isRecovery bool) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func (n *recordingNotifier) SendAggregatedNotification(alerts []*db.AlertStatus, isRecovery bool) error {
+	n.alerts = append(n.alerts, alerts...)
+	return nil
+}
+
+func (n *recordingNotifier) SendReport(title, content string) error { return nil }
+
+func (n *recordingNotifier) Close() error { return nil }
+
+func TestProcessRenotifyRecordsOnlyRenotify(t *testing.T) {
+	manager, _, log := newTestManagers(t)
+
+	handler := &recordingHandler{}
+	reminder := &recordingNotifier{}
+	c := NewConsumer(manager, nil, log, time.Second, handler)
+	c.policy = &RenotifyPolicy{Interval: 30 * time.Minute, Default: []types.Notifier{reminder}}
+
+	now := time.Now()
+	failTime := now.Add(-time.Hour).Format(time.RFC3339)
+	manager.RecordFailure(db.AlertStatus{Host: "a", Status: db.StatusAlert, FailTime: failTime}, 1)
+	c.processEvents(db.StatusAlert, "alerts")
+
+	c.processRenotify(now.Add(time.Hour))
+	if len(handler.alerts) != 1 || len(reminder.alerts) != 1 {
+		t.Fatalf("got %d aggregated and %d direct notifications, want 1 and 1", len(handler.alerts), len(reminder.alerts))
+	}
+
+	incidents, err := manager.GetIncidents("a", now.Add(-2*time.Hour), now.Add(2*time.Hour))
+	if err != nil || len(incidents) != 1 {
+		t.Fatalf("got %d incidents, err=%v", len(incidents), err)
+	}
+	var kinds []string
+	for _, n := range incidents[0].Notifications {
+		kinds = append(kinds, n.Type)
+	}
+	if strings.Join(kinds, ",") != "alert,renotify" {
+		t.Errorf("got notifications %v, want [alert renotify]", kinds)
+	}
+}
+
+func TestEscalatedRecoveryWaitsForDefaultNotifiers(t *testing.T) {
+	manager, _, log := newTestManagers(t)
+	handler := &recordingHandler{recoveryErr: errors.New("webhook unavailable")}
+	escalated := &recordingNotifier{}
+	c := NewConsumer(manager, nil, log, time.Second, handler)
+	c.policy = &RenotifyPolicy{Levels: []EscalationLevel{{After: time.Minute, Notifiers: []types.Notifier{escalated}}}}
+
+	now := time.Now()
+	manager.RecordFailure(db.AlertStatus{Host: "a", Status: db.StatusAlert, FailTime: now.Add(-time.Hour).Format(time.RFC3339)}, 1)
+	c.processEvents(db.StatusAlert, "alerts")
+	c.processRenotify(now)
+	escalated.alerts = nil
+
+	manager.RecordSuccess(db.AlertStatus{Host: "a", Status: db.StatusRecovery, RecoveryTime: now.Format(time.RFC3339)}, 1)
+	c.processEvents(db.StatusRecovery, "recoveries")
+	c.processEvents(db.StatusRecovery, "recoveries")
+	if len(escalated.alerts) != 0 {
+		t.Fatalf("escalation notifier got %d recoveries while the default send failed", len(escalated.alerts))
+	}
+
+	handler.recoveryErr = nil
+	c.processEvents(db.StatusRecovery, "recoveries")
+	c.processEvents(db.StatusRecovery, "recoveries")
+	if len(escalated.alerts) != 1 {
+		t.Fatalf("escalation notifier got %d recoveries, want 1", len(escalated.alerts))
+	}
+}
@@ -18,31 +18,66 @@ func RegisterNotifier(typeName string, creator NotifierCreator) {
 	notifierRegistry[typeName] = creator
 }
 
-// CreateNotifiers 从配置创建所有通知器
+// CreateNotifiers 从配置创建所有通知器，只用于告警升级的通知器不包含在内
 func CreateNotifiers(cfg *config.Config, logger *logger.Logger) []types.Notifier {
 	var notifiers []types.Notifier
 
 	for _, notifierCfg := range cfg.Alert.Notifiers {
-		if !notifierCfg.Enable {
-			logger.Log(fmt.Sprintf("Notifier %s is disabled, skipping", notifierCfg.Name), "debug")
+		if cfg.Alert.IsEscalationNotifier(notifierCfg.Name) {
+			logger.Log(fmt.Sprintf("Notifier %s is reserved for escalations, skipping", notifierCfg.Name), "debug")
 			continue
 		}
-
-		creator, exists := notifierRegistry[notifierCfg.Type]
-		if !exists {
-			logger.Log(fmt.Sprintf("Unknown notifier type: %s", notifierCfg.Type), "error")
-			continue
+		if notifier, ok := createNotifier(notifierCfg, logger); ok {
+			notifiers = append(notifiers, notifier)
 		}
+	}
 
-		notifier, err := creator(notifierCfg.Options, logger)
-		if err != nil {
-			logger.Log(fmt.Sprintf("Failed to initialize notifier %s: %v", notifierCfg.Name, err), "error")
-			continue
-		}
+	return notifiers
+}
 
-		notifiers = append(notifiers, notifier)
-		logger.Log(fmt.Sprintf("Successfully initialized notifier %s", notifierCfg.Name), "debug")
+// CreateNotifiersByName 按名称创建通知器，未找到或未启用的名称会被跳过
+func CreateNotifiersByName(cfg *config.Config, names []string, logger *logger.Logger) []types.Notifier {
+	var notifiers []types.Notifier
+
+	for _, name := range names {
+		found := false
+		for _, notifierCfg := range cfg.Alert.Notifiers {
+			if notifierCfg.Name != name {
+				continue
+			}
+			found = true
+			if notifier, ok := createNotifier(notifierCfg, logger); ok {
+				notifiers = append(notifiers, notifier)
+			}
+			break
+		}
+		if !found {
+			logger.Log(fmt.Sprintf("Notifier %s not found in configuration", name), "warn")
+		}
 	}
 
 	return notifiers
 }
+
+// createNotifier 根据单个通知器配置创建通知器
+func createNotifier(notifierCfg config.NotifierConfig, logger *logger.Logger) (types.Notifier, bool) {
+	if !notifierCfg.Enable {
+		logger.Log(fmt.Sprintf("Notifier %s is disabled, skipping", notifierCfg.Name), "debug")
+		return nil, false
+	}
+
+	creator, exists := notifierRegistry[notifierCfg.Type]
+	if !exists {
+		logger.Log(fmt.Sprintf("Unknown notifier type: %s", notifierCfg.Type), "error")
+		return nil, false
+	}
+
+	notifier, err := creator(notifierCfg.Options, logger)
+	if err != nil {
+		logger.Log(fmt.Sprintf("Failed to initialize notifier %s: %v", notifierCfg.Name, err), "error")
+		return nil, false
+	}
+
+	logger.Log(fmt.Sprintf("Successfully initialized notifier %s", notifierCfg.Name), "debug")
+	return notifier, true
+}
@@ -2,14 +2,17 @@ package notifier
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
 	"easy-check/internal/config"
 	"easy-check/internal/db"
 	"easy-check/internal/logger"
 	"easy-check/internal/types"
-	"easy-check/internal/utils"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -25,25 +28,27 @@ const (
 	OptionKeyAlertContent    FeishuOptionKey = "alert_content"
 	OptionKeyRecoveryTitle   FeishuOptionKey = "recovery_title"
 	OptionKeyRecoveryContent FeishuOptionKey = "recovery_content"
+	OptionKeyDegradedTitle   FeishuOptionKey = "degraded_title"
+	OptionKeyDegradedContent FeishuOptionKey = "degraded_content"
+	OptionKeyFlappingTitle   FeishuOptionKey = "flapping_title"
+	OptionKeyFlappingContent FeishuOptionKey = "flapping_content"
+	OptionKeyDashboardURL    FeishuOptionKey = "dashboard_url"  // 卡片消息中按钮跳转的地址
+	OptionKeyDashboardText   FeishuOptionKey = "dashboard_text" // 卡片消息中按钮的文字
+	OptionKeySecret          FeishuOptionKey = "secret"         // 签名校验的密钥
+	OptionKeyKeyword         FeishuOptionKey = "keyword"        // 自定义关键词，可配置一个或多个
 )
 
 // FeishuNotifier 飞书通知器
 type FeishuNotifier struct {
 	WebhookURL string
 	MsgType    string
+	Secret     string   // 机器人开启签名校验时的密钥，为空时不签名
+	Keywords   []string // 机器人开启自定义关键词时，标题中不含任一关键词则追加第一个
 	Logger     *logger.Logger
 	Config     *config.Config
 	Options    map[string]interface{} // 从 NotifierConfig.Options 中读取
 }
 
-// FeishuTextMessage 飞书文本消息结构
-type FeishuTextMessage struct {
-	MsgType string `json:"msg_type"`
-	Content struct {
-		Text string `json:"text"`
-	} `json:"content"`
-}
-
 // FeishuResponse 飞书 API 响应结构
 type FeishuResponse struct {
 	Code int         `json:"code"`
@@ -51,99 +56,25 @@ type FeishuResponse struct {
 	Data interface{} `json:"data"`
 }
 
-// TemplateData 定义用于模板渲染的数据结构
-type TemplateData struct {
-	Date       string
-	Time       string
-	AlertCount int
-	AlertList  string
-	Alerts     []*db.AlertStatus
-}
-
-// FeishuMessageSender 消息发送器接口
-type FeishuMessageSender interface {
-	PrepareMessage(title, content string) ([]byte, error)
-}
-
-// TextMessageSender 文本消息发送器
-type TextMessageSender struct{}
-
-// TODO Post 卡片消息发送器
-type PostMessageSender struct{}
-
-// TODO Interactive 卡片消息发送器
-type InteractiveMessageSender struct{}
-
 // 实现 Notifier 接口的 SendNotification 方法
 func (f *FeishuNotifier) SendNotification(alert *db.AlertStatus, isRecovery bool) error {
-	// 根据类型设置标题和模板
-	var titleKey, contentKey FeishuOptionKey
-	var defaultTitle, defaultTemplate string
-
-	if isRecovery {
-		titleKey = OptionKeyRecoveryTitle
-		contentKey = OptionKeyRecoveryContent
-		defaultTitle = "💚【easy-check】：恢复通知"
-		defaultTemplate = "🧭【恢复时间】：{{.RecoveryTime}}\n📝【恢复详情】：以下主机已恢复：\n- 开始时间：{{.FailTime}} | 主机：{{.Host}} | 描述：{{.Description}} | 恢复时间：{{.RecoveryTime}}"
-	} else {
-		titleKey = OptionKeyAlertTitle
-		contentKey = OptionKeyAlertContent
-		defaultTitle = "💔【easy-check】：告警通知"
-		defaultTemplate = "🧭【告警时间】：{{.Date}} {{.Time}}\n📝【告警详情】：以下主机不可达：\n- 开始时间：{{.FailTime}} | 主机：{{.Host}} | 描述：{{.Description}}"
-	}
-
-	// 获取标题
-	title, ok := f.Options[string(titleKey)].(string)
-	if !ok || title == "" {
-		title = defaultTitle
-	}
-
-	// 获取模板
-	templateContent, ok := f.Options[string(contentKey)].(string)
-	if !ok || templateContent == "" {
-		templateContent = defaultTemplate
-	}
-
-	// 准备模板数据
-	data := map[string]string{
-		"Date":         time.Now().Format("2006-01-02"),
-		"Time":         time.Now().Format("15:04:05"),
-		"Host":         alert.Host,
-		"Description":  alert.Description,
-		"FailTime":     utils.FormatTime(alert.FailTime),
-		"RecoveryTime": "", // 默认值为空字符串
-	}
-
-	// 检查 RecoveryTime 是否存在
-	if alert.RecoveryTime != "" {
-		data["RecoveryTime"] = utils.FormatTime(alert.RecoveryTime)
-	}
-
-	// 使用模板生成消息内容
-	var buffer bytes.Buffer
-	tmpl, err := template.New("notification").Parse(templateContent)
+	// 根据类型渲染标题和内容
+	title, content, err := renderNotification(f.Options, alert, isRecovery)
 	if err != nil {
-		f.Logger.Log(fmt.Sprintf("Error parsing template: %v", err), "error")
-		return fmt.Errorf("failed to parse template: %v", err)
-	}
-
-	if err := tmpl.Execute(&buffer, data); err != nil {
-		f.Logger.Log(fmt.Sprintf("Error applying template: %v", err), "error")
-		return fmt.Errorf("failed to apply template: %v", err)
+		f.Logger.Log(fmt.Sprintf("Error rendering notification: %v", err), "error")
+		return err
 	}
-
-	content := buffer.String()
 	f.Logger.Log(fmt.Sprintf("Generated notification content: %s", content), "debug")
 
 	// 准备完整消息（包含标题和内容）
-	sender := &TextMessageSender{}
-	message, err := sender.PrepareMessage(title, content)
+	sender := f.newSender([]*db.AlertStatus{alert}, isRecovery)
+	message, err := sender.PrepareMessage(f.withKeyword(title), content)
 	if err != nil {
 		return fmt.Errorf("failed to concatenate title and content: %v", err)
 	}
 
 	// 发送消息
-	err = f.sendMessage(string(message))
+	err = f.sendMessage(message)
 	if err != nil {
 		return fmt.Errorf("failed to send notification: %v", err)
 	}
@@ -166,6 +97,8 @@ func NewFeishuNotifier(options map[string]interface{}, logger *logger.Logger) (t
 	return &FeishuNotifier{
 		WebhookURL: webhookURL,
 		MsgType:    msgType,
+		Secret:     optionString(options, string(OptionKeySecret), ""),
+		Keywords:   optionStrings(options, string(OptionKeyKeyword)),
 		Logger:     logger,
 		Options:    options,
 	}, nil
@@ -203,20 +136,68 @@ func (f *FeishuNotifier) prepareContent(host config.Host, failTime time.Time) (s
 	return buffer.String(), nil
 }
 
-// sendMessage 发送消息
-func (f *FeishuNotifier) sendMessage(content string) error {
-	// 打印发送的消息内容
-	f.Logger.Log(fmt.Sprintf("Sending message: %s", content), "debug")
+// newSender 根据消息类型创建消息发送器，alerts 为空表示与告警无关的消息（如报告）
+// 配置了对应的 *_content 模板时，富文本和卡片消息在主机表格上方展示渲染的内容
+func (f *FeishuNotifier) newSender(alerts []*db.AlertStatus, isRecovery bool) FeishuMessageSender {
+	showContent := len(alerts) > 0 && optionString(f.Options, selectTemplate(alerts[0], isRecovery).contentKey, "") != ""
+	switch f.MsgType {
+	case "post":
+		return &PostMessageSender{Alerts: alerts, IsRecovery: isRecovery, ShowContent: showContent}
+	case "interactive":
+		return &InteractiveMessageSender{
+			Alerts:        alerts,
+			IsRecovery:    isRecovery,
+			ShowContent:   showContent,
+			DashboardURL:  optionString(f.Options, string(OptionKeyDashboardURL), ""),
+			DashboardText: optionString(f.Options, string(OptionKeyDashboardText), ""),
+		}
+	default:
+		return &TextMessageSender{}
+	}
+}
 
-	// 构造飞书消息
-	message := FeishuTextMessage{
-		MsgType: "text",
+// withKeyword 标题中不含任一关键词时追加第一个关键词，保证通过机器人的关键词校验
+func (f *FeishuNotifier) withKeyword(title string) string {
+	if len(f.Keywords) == 0 {
+		return title
+	}
+	for _, keyword := range f.Keywords {
+		if strings.Contains(title, keyword) {
+			return title
+		}
 	}
-	message.Content.Text = content
+	return fmt.Sprintf("%s %s", title, f.Keywords[0])
+}
 
-	data, err := json.Marshal(message)
-	if err != nil {
-		return fmt.Errorf("failed to marshal message: %v", err)
+// feishuSign 按飞书签名校验规则计算签名：以 "timestamp\nsecret" 为密钥对空字符串做 HmacSHA256，再做 Base64 编码
+func feishuSign(secret string, timestamp int64) string {
+	mac := hmac.New(sha256.New, []byte(fmt.Sprintf("%d\n%s", timestamp, secret)))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signMessage 在请求体中加入 timestamp 和 sign 字段
+func (f *FeishuNotifier) signMessage(data []byte, now time.Time) ([]byte, error) {
+	var body map[string]interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("failed to decode message: %v", err)
+	}
+	timestamp := now.Unix()
+	body["timestamp"] = strconv.FormatInt(timestamp, 10)
+	body["sign"] = feishuSign(f.Secret, timestamp)
+	return json.Marshal(body)
+}
+
+// sendMessage 发送消息，data 为发送器生成的完整请求体，配置了密钥时会先签名
+func (f *FeishuNotifier) sendMessage(data []byte) error {
+	// 打印发送的消息内容
+	f.Logger.Log(fmt.Sprintf("Sending message: %s", data), "debug")
+
+	if f.Secret != "" {
+		signed, err := f.signMessage(data, time.Now())
+		if err != nil {
+			return err
+		}
+		data = signed
 	}
 
 	// 发送 HTTP 请求
@@ -241,11 +222,18 @@ func (f *FeishuNotifier) sendMessage(content string) error {
 	return nil
 }
 
-// PrepareMessage 简单拼接标题和内容
-func (s *TextMessageSender) PrepareMessage(title, content string) ([]byte, error) {
-	// 简单拼接标题和内容，用换行分隔
-	message := fmt.Sprintf("%s\n%s", title, content)
-	return []byte(message), nil
+// SendReport 发送报告消息，如定期可用性报告
+func (f *FeishuNotifier) SendReport(title, content string) error {
+	sender := f.newSender(nil, false)
+	message, err := sender.PrepareMessage(f.withKeyword(title), content)
+	if err != nil {
+		return fmt.Errorf("failed to prepare message: %v", err)
+	}
+	if err := f.sendMessage(message); err != nil {
+		return fmt.Errorf("failed to send report: %v", err)
+	}
+	f.Logger.Log("Successfully sent report via Feishu", "debug")
+	return nil
 }
 
 func (n *FeishuNotifier) Close() error {
@@ -256,129 +244,29 @@ func (n *FeishuNotifier) Close() error {
 
 // PrepareAggregatedContent 通用方法，准备聚合内容（告警或恢复）
 func (f *FeishuNotifier) PrepareAggregatedContent(alerts []*db.AlertStatus, isRecovery bool) (string, error) {
-	// 检查输入是否为空
-	if len(alerts) == 0 {
-		return "", fmt.Errorf("no alerts to process")
-	}
-
-	// 根据类型选择模板
-	var lineTemplateContent, aggregateTemplateContent string
-	if isRecovery {
-		lineTemplateContent, _ = f.Options["recovery_line_template"].(string)
-		aggregateTemplateContent, _ = f.Options[string(OptionKeyRecoveryContent)].(string)
-		if lineTemplateContent == "" {
-			lineTemplateContent = "- 开始时间：{{.FailTime}} | 恢复时间：{{.RecoveryTime}} | 主机：{{.Host}} | 描述：{{.Description}}"
-		}
-		if aggregateTemplateContent == "" {
-			aggregateTemplateContent = "🧭【发送时间】：{{.Date}} {{.Time}}\n📝【恢复详情】：以下 {{.AlertCount}} 主机已恢复：\n{{.AlertList}}"
-		}
-	} else {
-		lineTemplateContent, _ = f.Options["alert_line_template"].(string)
-		aggregateTemplateContent, _ = f.Options[string(OptionKeyAlertContent)].(string)
-		if lineTemplateContent == "" {
-			lineTemplateContent = "- 开始时间：{{.FailTime}} | 主机：{{.Host}} | 描述：{{.Description}}"
-		}
-		if aggregateTemplateContent == "" {
-			aggregateTemplateContent = "🧭【告警时间】：{{.Date}} {{.Time}}\n📝【告警详情】：以下 {{.AlertCount}} 个主机不可达：\n{{.AlertList}}"
-		}
-	}
-
-	// 根据行模板生成 AlertList
-	alertList := make([]string, len(alerts))
-	for i, alert := range alerts {
-		data := struct {
-			Host         string
-			Description  string
-			FailTime     string
-			RecoveryTime string
-			Date         string
-			Time         string
-		}{
-			Host:         alert.Host,
-			Description:  alert.Description,
-			FailTime:     utils.FormatTime(alert.FailTime),
-			RecoveryTime: utils.FormatTime(alert.RecoveryTime),
-			Date:         time.Now().Format("2006-01-02"),
-			Time:         time.Now().Format("15:04:05"),
-		}
-
-		var buffer bytes.Buffer
-		tmpl, err := template.New("line").Parse(lineTemplateContent)
-		if err != nil {
-			f.Logger.Log(fmt.Sprintf("Error parsing line template: %v", err), "error")
-			return "", fmt.Errorf("failed to parse line template: %v", err)
-		}
-
-		if err := tmpl.Execute(&buffer, data); err != nil {
-			f.Logger.Log(fmt.Sprintf("Error applying line template: %v", err), "error")
-			return "", fmt.Errorf("failed to apply line template: %v", err)
-		}
-
-		alertList[i] = buffer.String()
-	}
-
-	// 将 AlertList 拼接为字符串
-	alertListStr := strings.Join(alertList, "\n")
-
-	// 准备聚合模板数据
-	data := TemplateData{
-		Date:       time.Now().Format("2006-01-02"),
-		Time:       time.Now().Format("15:04:05"),
-		AlertCount: len(alerts),
-		AlertList:  alertListStr,
-		Alerts:     alerts,
-	}
-
-	var buffer bytes.Buffer
-	tmpl, err := template.New("aggregate").Parse(aggregateTemplateContent)
-	if err != nil {
-		f.Logger.Log(fmt.Sprintf("Error parsing aggregate template: %v", err), "error")
-		return "", fmt.Errorf("failed to parse aggregate template: %v", err)
-	}
-
-	if err := tmpl.Execute(&buffer, data); err != nil {
-		f.Logger.Log(fmt.Sprintf("Error applying aggregate template: %v", err), "error")
-		return "", fmt.Errorf("failed to apply aggregate template: %v", err)
-	}
-
-	return buffer.String(), nil
+	_, content, err := renderAggregatedNotification(f.Options, alerts, isRecovery)
+	return content, err
 }
 
 // SendAggregatedNotification 通用方法，发送聚合通知（告警或恢复）
 func (f *FeishuNotifier) SendAggregatedNotification(alerts []*db.AlertStatus, isRecovery bool) error {
-	// 准备聚合内容
-	content, err := f.PrepareAggregatedContent(alerts, isRecovery)
+	// 准备聚合标题和内容
+	title, content, err := renderAggregatedNotification(f.Options, alerts, isRecovery)
 	if err != nil {
 		f.Logger.Log(fmt.Sprintf("Error preparing aggregated content: %v", err), "error")
 		return err
 	}
 
-	// 获取标题
-	var titleKey FeishuOptionKey
-	var defaultTitle string
-	if isRecovery {
-		titleKey = OptionKeyRecoveryTitle
-		defaultTitle = "💚【easy-check】：恢复通知"
-	} else {
-		titleKey = OptionKeyAlertTitle
-		defaultTitle = "💔【easy-check】：告警通知"
-	}
-
-	title, ok := f.Options[string(titleKey)].(string)
-	if !ok || title == "" {
-		title = defaultTitle
-	}
-
 	// 准备完整消息
-	sender := &TextMessageSender{}
-	message, err := sender.PrepareMessage(title, content)
+	sender := f.newSender(alerts, isRecovery)
+	message, err := sender.PrepareMessage(f.withKeyword(title), content)
 	if err != nil {
 		f.Logger.Log(fmt.Sprintf("Error preparing message: %v", err), "error")
 		return fmt.Errorf("failed to prepare message: %v", err)
 	}
 
 	// 发送消息
-	err = f.sendMessage(string(message))
+	err = f.sendMessage(message)
 	if err != nil {
 		return err
 	}
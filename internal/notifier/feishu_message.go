@@ -0,0 +1,229 @@
+package notifier
+
+import (
+	"easy-check/internal/db"
+	"easy-check/internal/utils"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FeishuMessageSender 消息发送器接口，返回飞书机器人 webhook 的完整请求体
+type FeishuMessageSender interface {
+	PrepareMessage(title, content string) ([]byte, error)
+}
+
+// FeishuTextMessage 飞书文本消息结构
+type FeishuTextMessage struct {
+	MsgType string `json:"msg_type"`
+	Content struct {
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// FeishuPostMessage 飞书富文本消息结构
+type FeishuPostMessage struct {
+	MsgType string `json:"msg_type"`
+	Content struct {
+		Post struct {
+			ZhCN FeishuPost `json:"zh_cn"`
+		} `json:"post"`
+	} `json:"content"`
+}
+
+// FeishuPost 富文本内容，Content 中每个元素为一个段落
+type FeishuPost struct {
+	Title   string                `json:"title"`
+	Content [][]FeishuPostElement `json:"content"`
+}
+
+type FeishuPostElement struct {
+	Tag   string   `json:"tag"`
+	Text  string   `json:"text"`
+	Style []string `json:"style,omitempty"`
+}
+
+// FeishuCardMessage 飞书卡片消息结构
+type FeishuCardMessage struct {
+	MsgType string     `json:"msg_type"`
+	Card    FeishuCard `json:"card"`
+}
+
+type FeishuCard struct {
+	Config struct {
+		WideScreenMode bool `json:"wide_screen_mode"`
+	} `json:"config"`
+	Header struct {
+		Title    FeishuCardText `json:"title"`
+		Template string         `json:"template"` // 标题栏颜色，如 red、green
+	} `json:"header"`
+	Elements []FeishuCardElement `json:"elements"`
+}
+
+// FeishuCardElement 卡片元素，Tag 为 div、hr 或 action
+type FeishuCardElement struct {
+	Tag     string             `json:"tag"`
+	Text    *FeishuCardText    `json:"text,omitempty"`
+	Fields  []FeishuCardField  `json:"fields,omitempty"`
+	Actions []FeishuCardButton `json:"actions,omitempty"`
+}
+
+type FeishuCardText struct {
+	Tag     string `json:"tag"` // plain_text 或 lark_md
+	Content string `json:"content"`
+}
+
+type FeishuCardField struct {
+	IsShort bool           `json:"is_short"`
+	Text    FeishuCardText `json:"text"`
+}
+
+type FeishuCardButton struct {
+	Tag  string         `json:"tag"`
+	Text FeishuCardText `json:"text"`
+	Type string         `json:"type"`
+	URL  string         `json:"url"`
+}
+
+// TextMessageSender 文本消息发送器
+type TextMessageSender struct{}
+
+// PostMessageSender 富文本消息发送器：加粗标题，主机以表格形式逐行展示
+// 没有主机时（如报告）按行展示内容
+type PostMessageSender struct {
+	Alerts      []*db.AlertStatus
+	IsRecovery  bool
+	ShowContent bool // 有主机时也在表格上方展示模板渲染的内容，用于自定义了内容模板的通知器
+}
+
+// InteractiveMessageSender 卡片消息发送器：标题栏颜色区分告警和恢复，每个主机一组字段，可附带跳转按钮
+// 没有主机时（如报告）展示内容
+type InteractiveMessageSender struct {
+	Alerts        []*db.AlertStatus
+	IsRecovery    bool
+	ShowContent   bool   // 有主机时也在字段上方展示模板渲染的内容，用于自定义了内容模板的通知器
+	DashboardURL  string // 为空时不显示按钮
+	DashboardText string
+}
+
+// PrepareMessage 简单拼接标题和内容
+func (s *TextMessageSender) PrepareMessage(title, content string) ([]byte, error) {
+	// 简单拼接标题和内容，用换行分隔
+	message := FeishuTextMessage{MsgType: "text"}
+	message.Content.Text = fmt.Sprintf("%s\n%s", title, content)
+	return json.Marshal(message)
+}
+
+// PrepareMessage 生成富文本消息，有主机时只在 ShowContent 为 true 时展示模板渲染的内容
+func (s *PostMessageSender) PrepareMessage(title, content string) ([]byte, error) {
+	post := FeishuPost{Title: title}
+	if len(s.Alerts) == 0 || s.ShowContent {
+		for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+			post.Content = append(post.Content, []FeishuPostElement{{Tag: "text", Text: line}})
+		}
+	}
+	if len(s.Alerts) > 0 {
+		post.Content = append(post.Content, []FeishuPostElement{{Tag: "text", Text: summaryLine(len(s.Alerts))}})
+
+		columns := alertColumns(s.Alerts[0], s.IsRecovery)
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = column[0]
+		}
+		post.Content = append(post.Content, []FeishuPostElement{{Tag: "text", Text: strings.Join(header, " | "), Style: []string{"bold"}}})
+		for _, alert := range s.Alerts {
+			columns := alertColumns(alert, s.IsRecovery)
+			row := make([]string, len(columns))
+			for i, column := range columns {
+				row[i] = column[1]
+			}
+			post.Content = append(post.Content, []FeishuPostElement{{Tag: "text", Text: strings.Join(row, " | ")}})
+		}
+	}
+
+	message := FeishuPostMessage{MsgType: "post"}
+	message.Content.Post.ZhCN = post
+	return json.Marshal(message)
+}
+
+// PrepareMessage 生成卡片消息，有主机时只在 ShowContent 为 true 时展示模板渲染的内容
+func (s *InteractiveMessageSender) PrepareMessage(title, content string) ([]byte, error) {
+	var card FeishuCard
+	card.Config.WideScreenMode = true
+	card.Header.Title = FeishuCardText{Tag: "plain_text", Content: title}
+	card.Header.Template = cardColor(s.Alerts, s.IsRecovery)
+
+	if len(s.Alerts) == 0 || s.ShowContent {
+		card.Elements = append(card.Elements, FeishuCardElement{Tag: "div", Text: &FeishuCardText{Tag: "lark_md", Content: content}})
+	}
+	if len(s.Alerts) > 0 {
+		card.Elements = append(card.Elements, FeishuCardElement{Tag: "div", Text: &FeishuCardText{Tag: "lark_md", Content: summaryLine(len(s.Alerts))}})
+		for _, alert := range s.Alerts {
+			element := FeishuCardElement{Tag: "div"}
+			for _, column := range alertColumns(alert, s.IsRecovery) {
+				element.Fields = append(element.Fields, FeishuCardField{
+					IsShort: true,
+					Text:    FeishuCardText{Tag: "lark_md", Content: fmt.Sprintf("**%s**\n%s", column[0], column[1])},
+				})
+			}
+			card.Elements = append(card.Elements, FeishuCardElement{Tag: "hr"}, element)
+		}
+	}
+
+	if s.DashboardURL != "" {
+		text := s.DashboardText
+		if text == "" {
+			text = "查看详情"
+		}
+		card.Elements = append(card.Elements, FeishuCardElement{
+			Tag: "action",
+			Actions: []FeishuCardButton{{
+				Tag:  "button",
+				Text: FeishuCardText{Tag: "plain_text", Content: text},
+				Type: "primary",
+				URL:  s.DashboardURL,
+			}},
+		})
+	}
+
+	return json.Marshal(FeishuCardMessage{MsgType: "interactive", Card: card})
+}
+
+// summaryLine 富文本和卡片消息开头的发送时间和主机数
+func summaryLine(count int) string {
+	return fmt.Sprintf("🧭 发送时间：%s | 共 %d 个主机", time.Now().Format("2006-01-02 15:04:05"), count)
+}
+
+// alertColumns 返回主机在表格或卡片中展示的列（名称，值），同一批次的列相同
+func alertColumns(alert *db.AlertStatus, isRecovery bool) [][2]string {
+	columns := [][2]string{{"主机", alert.Host}, {"描述", alert.Description}}
+	switch {
+	case alert.IsFlapNotice():
+		return append(columns, [2]string{"详情", alert.Detail})
+	case isRecovery:
+		return append(columns,
+			[2]string{"开始时间", utils.FormatTime(alert.FailTime)},
+			[2]string{"恢复时间", utils.FormatTime(alert.RecoveryTime) + ackNote(alert)})
+	default:
+		return append(columns,
+			[2]string{"开始时间", utils.FormatTime(alert.FailTime)},
+			[2]string{"原因", alert.Detail + dependentNote(alert)})
+	}
+}
+
+// cardColor 卡片标题栏颜色：告警红色、恢复绿色、降级橙色、抖动紫色，报告蓝色
+func cardColor(alerts []*db.AlertStatus, isRecovery bool) string {
+	switch {
+	case len(alerts) == 0:
+		return "blue"
+	case alerts[0].IsFlapNotice():
+		return "purple"
+	case isRecovery:
+		return "green"
+	case alerts[0].Status == db.StatusDegraded:
+		return "orange"
+	default:
+		return "red"
+	}
+}
@@ -0,0 +1,101 @@
+package notifier
+
+import (
+	"easy-check/internal/db"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFeishuRichMessageSenders(t *testing.T) {
+	alerts := []*db.AlertStatus{
+		{Host: "10.0.0.1", Description: "网关", Status: db.StatusRecovery, FailTime: "2026-10-14T09:00:00+08:00", RecoveryTime: "2026-10-14T09:05:00+08:00"},
+		{Host: "10.0.0.2", Description: "交换机", Status: db.StatusRecovery, FailTime: "2026-10-14T09:01:00+08:00", RecoveryTime: "2026-10-14T09:05:00+08:00"},
+	}
+
+	data, err := (&PostMessageSender{Alerts: alerts, IsRecovery: true}).PrepareMessage("恢复通知", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var post FeishuPostMessage
+	if err := json.Unmarshal(data, &post); err != nil {
+		t.Fatal(err)
+	}
+	paragraphs := post.Content.Post.ZhCN.Content
+	if post.MsgType != "post" || post.Content.Post.ZhCN.Title != "恢复通知" || len(paragraphs) != 4 {
+		t.Fatalf("unexpected post message: %s", data)
+	}
+	if header := paragraphs[1][0]; header.Text != "主机 | 描述 | 开始时间 | 恢复时间" || header.Style[0] != "bold" {
+		t.Fatalf("unexpected table header: %+v", header)
+	}
+	if row := paragraphs[2][0].Text; row != "10.0.0.1 | 网关 | 2026-10-14 09:00:00 | 2026-10-14 09:05:00" {
+		t.Fatalf("unexpected table row: %q", row)
+	}
+
+	sender := &InteractiveMessageSender{Alerts: alerts[:1], DashboardURL: "http://127.0.0.1:32180/"}
+	data, err = sender.PrepareMessage("告警通知", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var card FeishuCardMessage
+	if err := json.Unmarshal(data, &card); err != nil {
+		t.Fatal(err)
+	}
+	if card.MsgType != "interactive" || card.Card.Header.Template != "red" {
+		t.Fatalf("unexpected card header: %s", data)
+	}
+	elements := card.Card.Elements
+	if len(elements) != 4 || len(elements[2].Fields) != 4 || !strings.Contains(elements[2].Fields[0].Text.Content, "10.0.0.1") {
+		t.Fatalf("unexpected card elements: %s", data)
+	}
+	if button := elements[3].Actions[0]; button.URL != sender.DashboardURL || button.Text.Content != "查看详情" {
+		t.Fatalf("unexpected button: %+v", button)
+	}
+
+	sender.IsRecovery = true
+	data, _ = sender.PrepareMessage("恢复通知", "")
+	if !strings.Contains(string(data), `"template":"green"`) {
+		t.Fatalf("expected green header for recovery: %s", data)
+	}
+}
+
+func TestFeishuRichMessageCustomContent(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		json.NewEncoder(w).Encode(FeishuResponse{Msg: "success"})
+	}))
+	defer server.Close()
+
+	log := newTestLogger(t)
+	alerts := []*db.AlertStatus{{Host: "10.0.0.1", Description: "网关", Status: db.StatusAlert, FailTime: "2026-10-14T09:00:00+08:00"}}
+
+	for _, msgType := range []string{"post", "interactive"} {
+		n, err := NewFeishuNotifier(map[string]interface{}{
+			"webhook":       server.URL,
+			"msg_type":      msgType,
+			"alert_content": "值班电话：10086\n共 {{.AlertCount}} 个主机",
+		}, log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := n.SendAggregatedNotification(alerts, false); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(body, "值班电话：10086") || !strings.Contains(body, "10.0.0.1") {
+			t.Errorf("%s: custom content or host missing: %s", msgType, body)
+		}
+
+		// 未配置的恢复模板不展示默认内容
+		if err := n.SendAggregatedNotification(alerts, true); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(body, "值班电话") || strings.Contains(body, "恢复详情") {
+			t.Errorf("%s: unexpected content in recovery message: %s", msgType, body)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package notifier
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFeishuSignatureAndKeyword(t *testing.T) {
+	const secret = "feishu-secret"
+	var received struct {
+		Timestamp string `json:"timestamp"`
+		Sign      string `json:"sign"`
+		Content   struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+		mac := hmac.New(sha256.New, []byte(received.Timestamp+"\n"+secret))
+		if received.Sign != base64.StdEncoding.EncodeToString(mac.Sum(nil)) {
+			json.NewEncoder(w).Encode(FeishuResponse{Code: 19021, Msg: "sign match fail or timestamp is not within one hour from current time"})
+			return
+		}
+		json.NewEncoder(w).Encode(FeishuResponse{Msg: "success"})
+	}))
+	defer server.Close()
+
+	log := newTestLogger(t)
+	n, err := NewFeishuNotifier(map[string]interface{}{
+		"webhook":  server.URL,
+		"msg_type": "text",
+		"secret":   secret,
+		"keyword":  []interface{}{"监控", "运维"},
+	}, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := n.SendReport("📊【easy-check】：可用性报告", "content"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(received.Content.Text, "📊【easy-check】：可用性报告 监控\n") {
+		t.Fatalf("expected keyword in title, got %q", received.Content.Text)
+	}
+
+	// 标题已包含关键词时不再追加
+	if err := n.SendReport("运维日报", "content"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(received.Content.Text, "运维日报\n") {
+		t.Fatalf("unexpected title: %q", received.Content.Text)
+	}
+
+	n.(*FeishuNotifier).Secret = "wrong"
+	if err := n.SendReport("监控", "content"); err == nil || !strings.Contains(err.Error(), "code=19021") {
+		t.Fatalf("expected sign error, got %v", err)
+	}
+}
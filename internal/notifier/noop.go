@@ -15,6 +15,10 @@ func (n *NoopNotifier) SendAggregatedNotification(alerts []*db.AlertStatus, isRe
 	return nil
 }
 
+func (n *NoopNotifier) SendReport(title, content string) error {
+	return nil
+}
+
 func (n *NoopNotifier) Close() error {
 	return nil
 }
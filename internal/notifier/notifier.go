@@ -79,6 +79,21 @@ func (m *MultiNotifierWrapper) SendAggregatedNotification(alerts []*db.AlertStat
 	return nil
 }
 
+// SendReport 将报告发送到所有通知器
+func (m *MultiNotifierWrapper) SendReport(title, content string) error {
+	var errs []error
+	for _, notifier := range m.Notifiers {
+		if err := notifier.SendReport(title, content); err != nil {
+			m.Logger.Log(fmt.Sprintf("Error sending report: %v", err), "error")
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
 // Close 实现 Notifier 接口，关闭所有启用的通知器
 func (m *MultiNotifierWrapper) Close() error {
 	var errs []error
@@ -0,0 +1,288 @@
+package notifier
+
+import (
+	"bytes"
+	"easy-check/internal/db"
+	"easy-check/internal/utils"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+// 以下配置项在飞书、钉钉等通知器中含义相同：
+// alert_title / alert_content、recovery_title / recovery_content、degraded_title / degraded_content、
+// flapping_title / flapping_content，以及聚合通知的 *_line_template
+
+// TemplateData 定义用于模板渲染的数据结构
+type TemplateData struct {
+	Date       string
+	Time       string
+	AlertCount int
+	AlertList  string
+	Alerts     []*db.AlertStatus
+}
+
+// alertTemplateData 单个主机通知和聚合通知每一行使用的模板数据
+type alertTemplateData struct {
+	Host          string
+	Description   string
+	FailTime      string
+	RecoveryTime  string
+	Reason        string
+	Detail        string
+	AckBy         string
+	AckComment    string
+	AckNote       string
+	Dependents    []string
+	DependentNote string
+	Date          string
+	Time          string
+}
+
+// notificationTemplate 一种通知类型的配置项和默认模板
+type notificationTemplate struct {
+	titleKey, contentKey, lineKey string
+	defaultTitle                  string
+	defaultContent                string // 单个主机通知的内容模板
+	defaultLine                   string // 聚合通知中每个主机一行的模板
+	defaultAggregate              string // 聚合通知的内容模板
+}
+
+var (
+	flappingTemplate = notificationTemplate{
+		titleKey:         string(OptionKeyFlappingTitle),
+		contentKey:       string(OptionKeyFlappingContent),
+		lineKey:          "flapping_line_template",
+		defaultTitle:     "💜【easy-check】：抖动通知",
+		defaultContent:   "🧭【通知时间】：{{.Date}} {{.Time}}\n📝【抖动详情】：以下主机状态频繁切换：\n- 主机：{{.Host}} | 描述：{{.Description}} | {{.Detail}}",
+		defaultLine:      "- 主机：{{.Host}} | 描述：{{.Description}} | {{.Detail}}",
+		defaultAggregate: "🧭【通知时间】：{{.Date}} {{.Time}}\n📝【抖动详情】：以下 {{.AlertCount}} 个主机状态频繁切换：\n{{.AlertList}}",
+	}
+	recoveryTemplate = notificationTemplate{
+		titleKey:         string(OptionKeyRecoveryTitle),
+		contentKey:       string(OptionKeyRecoveryContent),
+		lineKey:          "recovery_line_template",
+		defaultTitle:     "💚【easy-check】：恢复通知",
+		defaultContent:   "🧭【恢复时间】：{{.RecoveryTime}}\n📝【恢复详情】：以下主机已恢复：\n- 开始时间：{{.FailTime}} | 主机：{{.Host}} | 描述：{{.Description}} | 恢复时间：{{.RecoveryTime}}{{.AckNote}}",
+		defaultLine:      "- 开始时间：{{.FailTime}} | 恢复时间：{{.RecoveryTime}} | 主机：{{.Host}} | 描述：{{.Description}}{{.AckNote}}",
+		defaultAggregate: "🧭【发送时间】：{{.Date}} {{.Time}}\n📝【恢复详情】：以下 {{.AlertCount}} 主机已恢复：\n{{.AlertList}}",
+	}
+	degradedTemplate = notificationTemplate{
+		titleKey:         string(OptionKeyDegradedTitle),
+		contentKey:       string(OptionKeyDegradedContent),
+		lineKey:          "degraded_line_template",
+		defaultTitle:     "💛【easy-check】：降级通知",
+		defaultContent:   "🧭【降级时间】：{{.Date}} {{.Time}}\n📝【降级详情】：以下主机延迟过高：\n- 开始时间：{{.FailTime}} | 主机：{{.Host}} | 描述：{{.Description}} | 原因：{{.Detail}}",
+		defaultLine:      "- 开始时间：{{.FailTime}} | 主机：{{.Host}} | 描述：{{.Description}} | 原因：{{.Detail}}",
+		defaultAggregate: "🧭【降级时间】：{{.Date}} {{.Time}}\n📝【降级详情】：以下 {{.AlertCount}} 个主机延迟过高：\n{{.AlertList}}",
+	}
+	alertTemplate = notificationTemplate{
+		titleKey:         string(OptionKeyAlertTitle),
+		contentKey:       string(OptionKeyAlertContent),
+		lineKey:          "alert_line_template",
+		defaultTitle:     "💔【easy-check】：告警通知",
+		defaultContent:   "🧭【告警时间】：{{.Date}} {{.Time}}\n📝【告警详情】：以下主机检测异常：\n- 开始时间：{{.FailTime}} | 主机：{{.Host}} | 描述：{{.Description}} | 原因：{{.Detail}}{{.DependentNote}}",
+		defaultLine:      "- 开始时间：{{.FailTime}} | 主机：{{.Host}} | 描述：{{.Description}} | 原因：{{.Detail}}{{.DependentNote}}",
+		defaultAggregate: "🧭【告警时间】：{{.Date}} {{.Time}}\n📝【告警详情】：以下 {{.AlertCount}} 个主机检测异常：\n{{.AlertList}}",
+	}
+)
+
+// selectTemplate 根据通知类型选择模板，抖动开始/结束通知优先于状态本身
+func selectTemplate(alert *db.AlertStatus, isRecovery bool) notificationTemplate {
+	switch {
+	case alert.IsFlapNotice():
+		return flappingTemplate
+	case isRecovery:
+		return recoveryTemplate
+	case alert.Status == db.StatusDegraded:
+		return degradedTemplate
+	default:
+		return alertTemplate
+	}
+}
+
+// optionString 读取字符串配置项，未配置或为空时返回默认值
+func optionString(options map[string]interface{}, key, fallback string) string {
+	if value, ok := options[key].(string); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// optionStrings 读取字符串列表配置项，兼容单个字符串和未加引号的数字（如手机号）
+func optionStrings(options map[string]interface{}, key string) []string {
+	switch value := options[key].(type) {
+	case string:
+		if value != "" {
+			return []string{value}
+		}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			values = append(values, fmt.Sprint(v))
+		}
+		return values
+	}
+	return nil
+}
+
+// notificationTitle 返回通知的标题
+func notificationTitle(options map[string]interface{}, alert *db.AlertStatus, isRecovery bool) string {
+	t := selectTemplate(alert, isRecovery)
+	return optionString(options, t.titleKey, t.defaultTitle)
+}
+
+// renderNotification 根据通知器配置渲染单个主机通知的标题和内容
+func renderNotification(options map[string]interface{}, alert *db.AlertStatus, isRecovery bool) (title, content string, err error) {
+	t := selectTemplate(alert, isRecovery)
+	title = notificationTitle(options, alert, isRecovery)
+	content, err = renderTemplate("notification", optionString(options, t.contentKey, t.defaultContent), newAlertTemplateData(alert))
+	return title, content, err
+}
+
+// renderAggregatedNotification 根据通知器配置渲染聚合通知的标题和内容，同一批次的通知类型相同
+func renderAggregatedNotification(options map[string]interface{}, alerts []*db.AlertStatus, isRecovery bool) (title, content string, err error) {
+	title, contents, err := renderAggregatedChunks(options, alerts, isRecovery, 0)
+	if err != nil {
+		return "", "", err
+	}
+	return title, contents[0], nil
+}
+
+// renderAggregatedChunks 渲染聚合通知，maxBytes 大于 0 时按主机拆分为多条内容，每条都不超过 maxBytes 字节
+// 每条内容都使用完整的聚合模板，AlertCount 为该条包含的主机数；单个主机的内容仍超长时截断
+func renderAggregatedChunks(options map[string]interface{}, alerts []*db.AlertStatus, isRecovery bool, maxBytes int) (title string, contents []string, err error) {
+	if len(alerts) == 0 {
+		return "", nil, fmt.Errorf("no alerts to process")
+	}
+
+	t := selectTemplate(alerts[0], isRecovery)
+	title = notificationTitle(options, alerts[0], isRecovery)
+	aggregateTemplate := optionString(options, t.contentKey, t.defaultAggregate)
+	alertList, err := renderAlertList(options, alerts, isRecovery)
+	if err != nil {
+		return "", nil, err
+	}
+
+	render := func(from, to int) (string, error) {
+		data := TemplateData{
+			Date:       time.Now().Format("2006-01-02"),
+			Time:       time.Now().Format("15:04:05"),
+			AlertCount: to - from,
+			AlertList:  strings.Join(alertList[from:to], "\n"),
+			Alerts:     alerts[from:to],
+		}
+		return renderTemplate("aggregate", aggregateTemplate, data)
+	}
+
+	start := 0
+	var current string
+	for end := 1; end <= len(alerts); end++ {
+		content, err := render(start, end)
+		if err != nil {
+			return "", nil, err
+		}
+		if maxBytes > 0 && len(content) > maxBytes && end-start > 1 {
+			// 加入当前主机后超长，之前的主机单独成为一条
+			contents = append(contents, current)
+			start = end - 1
+			if content, err = render(start, end); err != nil {
+				return "", nil, err
+			}
+		}
+		current = content
+	}
+	contents = append(contents, current)
+
+	if maxBytes > 0 {
+		for i := range contents {
+			contents[i] = truncateBytes(contents[i], maxBytes)
+		}
+	}
+	return title, contents, nil
+}
+
+// renderAlertList 按 *_line_template 渲染聚合通知中每个主机的一行
+func renderAlertList(options map[string]interface{}, alerts []*db.AlertStatus, isRecovery bool) ([]string, error) {
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+	t := selectTemplate(alerts[0], isRecovery)
+	lineTemplate := optionString(options, t.lineKey, t.defaultLine)
+
+	lines := make([]string, len(alerts))
+	for i, alert := range alerts {
+		line, err := renderTemplate("line", lineTemplate, newAlertTemplateData(alert))
+		if err != nil {
+			return nil, err
+		}
+		lines[i] = line
+	}
+	return lines, nil
+}
+
+// truncateBytes 将内容截断到不超过 maxBytes 字节，不会截断在 UTF-8 字符中间
+func truncateBytes(content string, maxBytes int) string {
+	if len(content) <= maxBytes {
+		return content
+	}
+	if maxBytes <= 0 {
+		return ""
+	}
+	end := maxBytes
+	for end > 0 && !utf8.RuneStart(content[end]) {
+		end--
+	}
+	return content[:end]
+}
+
+func newAlertTemplateData(alert *db.AlertStatus) alertTemplateData {
+	return alertTemplateData{
+		Host:          alert.Host,
+		Description:   alert.Description,
+		FailTime:      utils.FormatTime(alert.FailTime),
+		RecoveryTime:  utils.FormatTime(alert.RecoveryTime),
+		Reason:        string(alert.Reason),
+		Detail:        alert.Detail,
+		AckBy:         alert.AckBy,
+		AckComment:    alert.AckComment,
+		AckNote:       ackNote(alert),
+		Dependents:    alert.Dependents,
+		DependentNote: dependentNote(alert),
+		Date:          time.Now().Format("2006-01-02"),
+		Time:          time.Now().Format("15:04:05"),
+	}
+}
+
+func renderTemplate(name, content string, data any) (string, error) {
+	tmpl, err := template.New(name).Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s template: %v", name, err)
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return "", fmt.Errorf("failed to apply %s template: %v", name, err)
+	}
+	return buffer.String(), nil
+}
+
+// ackNote 告警被确认过时返回确认人和备注，用于恢复通知
+func ackNote(alert *db.AlertStatus) string {
+	if alert.AckBy == "" {
+		return ""
+	}
+	if alert.AckComment == "" {
+		return fmt.Sprintf(" | 确认人：%s", alert.AckBy)
+	}
+	return fmt.Sprintf(" | 确认人：%s（%s）", alert.AckBy, alert.AckComment)
+}
+
+// dependentNote 主机故障导致下游主机不可达时返回受影响的下游主机数量
+func dependentNote(alert *db.AlertStatus) string {
+	if len(alert.Dependents) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" | 受影响的下游主机：%d 个", len(alert.Dependents))
+}
@@ -0,0 +1,240 @@
+package notifier
+
+import (
+	"bytes"
+	"easy-check/internal/db"
+	"easy-check/internal/logger"
+	"easy-check/internal/types"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 企业微信群机器人单条消息内容的长度上限（字节）
+const (
+	wecomTextMaxBytes     = 2048
+	wecomMarkdownMaxBytes = 4096
+	wecomPartReserve      = 32 // 为拆分后标题追加的序号（如 “（1/3）”）预留的长度
+)
+
+// WeComNotifier 企业微信群机器人通知器，标题和内容模板与飞书通知器使用相同的配置项
+// 内容超过企业微信的长度限制时拆分为多条消息发送
+type WeComNotifier struct {
+	WebhookURL          string
+	MsgType             string   // text 或 markdown
+	MentionedList       []string // 需要 @ 的成员 userid，@all 表示所有人
+	MentionedMobileList []string // 需要 @ 的成员手机号，仅 text 消息支持
+	Logger              *logger.Logger
+	Options             map[string]interface{}
+}
+
+// WeComMessage 企业微信机器人消息结构
+type WeComMessage struct {
+	MsgType  string         `json:"msgtype"`
+	Text     *WeComText     `json:"text,omitempty"`
+	Markdown *WeComMarkdown `json:"markdown,omitempty"`
+}
+
+type WeComText struct {
+	Content             string   `json:"content"`
+	MentionedList       []string `json:"mentioned_list,omitempty"`
+	MentionedMobileList []string `json:"mentioned_mobile_list,omitempty"`
+}
+
+type WeComMarkdown struct {
+	Content string `json:"content"`
+}
+
+// WeComResponse 企业微信 API 响应结构
+type WeComResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// NewWeComNotifier 创建企业微信通知器
+func NewWeComNotifier(options map[string]interface{}, logger *logger.Logger) (types.Notifier, error) {
+	webhookURL, ok := options["webhook"].(string)
+	if !ok || webhookURL == "" {
+		return nil, fmt.Errorf("missing webhook URL in WeCom notifier options")
+	}
+
+	msgType := optionString(options, "msg_type", "text")
+	if msgType != "text" && msgType != "markdown" {
+		return nil, fmt.Errorf("unsupported message type %q in WeCom notifier options", msgType)
+	}
+
+	return &WeComNotifier{
+		WebhookURL:          webhookURL,
+		MsgType:             msgType,
+		MentionedList:       optionStrings(options, "mentioned_list"),
+		MentionedMobileList: optionStrings(options, "mentioned_mobile_list"),
+		Logger:              logger,
+		Options:             options,
+	}, nil
+}
+
+// SendNotification 发送单个主机的告警/恢复通知
+func (w *WeComNotifier) SendNotification(alert *db.AlertStatus, isRecovery bool) error {
+	title, content, err := renderNotification(w.Options, alert, isRecovery)
+	if err != nil {
+		w.Logger.Log(fmt.Sprintf("Error rendering notification: %v", err), "error")
+		return err
+	}
+	limit, err := w.contentLimit(title)
+	if err != nil {
+		return err
+	}
+	if err := w.sendParts(title, splitLines(content, limit)); err != nil {
+		return fmt.Errorf("failed to send notification: %v", err)
+	}
+	w.Logger.Log("Successfully sent notification via WeCom", "debug")
+	return nil
+}
+
+// SendAggregatedNotification 发送聚合通知（告警或恢复），主机较多时按主机拆分为多条
+func (w *WeComNotifier) SendAggregatedNotification(alerts []*db.AlertStatus, isRecovery bool) error {
+	if len(alerts) == 0 {
+		return fmt.Errorf("no alerts to process")
+	}
+	title := notificationTitle(w.Options, alerts[0], isRecovery)
+	limit, err := w.contentLimit(title)
+	if err != nil {
+		return err
+	}
+	_, contents, err := renderAggregatedChunks(w.Options, alerts, isRecovery, limit)
+	if err != nil {
+		w.Logger.Log(fmt.Sprintf("Error preparing aggregated content: %v", err), "error")
+		return err
+	}
+	if err := w.sendParts(title, contents); err != nil {
+		return err
+	}
+	w.Logger.Log(fmt.Sprintf("Successfully sent aggregated notification via WeCom in %d message(s)", len(contents)), "debug")
+	return nil
+}
+
+// SendReport 发送报告消息，如定期可用性报告
+func (w *WeComNotifier) SendReport(title, content string) error {
+	limit, err := w.contentLimit(title)
+	if err != nil {
+		return err
+	}
+	if err := w.sendParts(title, splitLines(content, limit)); err != nil {
+		return fmt.Errorf("failed to send report: %v", err)
+	}
+	w.Logger.Log("Successfully sent report via WeCom", "debug")
+	return nil
+}
+
+func (w *WeComNotifier) Close() error {
+	w.Logger.Log("Closing WeComNotifier", "debug")
+	return nil
+}
+
+// contentLimit 返回标题为 title 时单条消息中内容部分的长度上限，标题和 @ 成员已占满时返回错误
+func (w *WeComNotifier) contentLimit(title string) (int, error) {
+	limit := wecomTextMaxBytes
+	if w.MsgType == "markdown" {
+		limit = wecomMarkdownMaxBytes
+	}
+	limit -= len(w.format(title, "", true)) + wecomPartReserve
+	if limit <= 0 {
+		return 0, fmt.Errorf("title and mentions exceed the WeCom %s message limit, no room for content", w.MsgType)
+	}
+	return limit, nil
+}
+
+// format 拼接单条消息的内容，markdown 消息通过 <@userid> 在正文中 @ 成员，不支持 @all
+func (w *WeComNotifier) format(title, content string, mention bool) string {
+	if w.MsgType != "markdown" {
+		return fmt.Sprintf("%s\n%s", title, content)
+	}
+	text := fmt.Sprintf("**%s**\n%s", title, content)
+	if mention {
+		for _, userID := range w.MentionedList {
+			if userID != "@all" {
+				text += fmt.Sprintf("<@%s>", userID)
+			}
+		}
+	}
+	return text
+}
+
+// sendParts 依次发送拆分后的各条消息，多于一条时在标题后追加序号，只在第一条中 @ 成员
+// 只有全部发送失败时返回错误；部分发送成功时只记录失败的部分，避免重试时重复发送已成功的消息
+func (w *WeComNotifier) sendParts(title string, contents []string) error {
+	var failed []string
+	var lastErr error
+	for i, content := range contents {
+		partTitle := title
+		if len(contents) > 1 {
+			partTitle = fmt.Sprintf("%s（%d/%d）", title, i+1, len(contents))
+		}
+
+		message := WeComMessage{MsgType: w.MsgType}
+		if w.MsgType == "markdown" {
+			message.Markdown = &WeComMarkdown{Content: w.format(partTitle, content, i == 0)}
+		} else {
+			message.Text = &WeComText{Content: w.format(partTitle, content, i == 0)}
+			if i == 0 {
+				message.Text.MentionedList = w.MentionedList
+				message.Text.MentionedMobileList = w.MentionedMobileList
+			}
+		}
+		if err := w.sendMessage(message); err != nil {
+			failed = append(failed, fmt.Sprintf("%d/%d", i+1, len(contents)))
+			lastErr = err
+		}
+	}
+	if len(failed) == len(contents) {
+		return lastErr
+	}
+	if len(failed) > 0 {
+		w.Logger.Log(fmt.Sprintf("Failed to send WeCom message part(s) %s of %q: %v", strings.Join(failed, ", "), title, lastErr), "error")
+	}
+	return nil
+}
+
+// sendMessage 发送消息
+func (w *WeComNotifier) sendMessage(message WeComMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %v", err)
+	}
+	w.Logger.Log(fmt.Sprintf("Sending WeCom message: %s", data), "debug")
+
+	resp, err := http.Post(w.WebhookURL, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to send HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var wecomResp WeComResponse
+	if err := json.NewDecoder(resp.Body).Decode(&wecomResp); err != nil {
+		return fmt.Errorf("failed to parse response: %v", err)
+	}
+	if wecomResp.ErrCode != 0 {
+		return fmt.Errorf("API error: code=%d, message=%s", wecomResp.ErrCode, wecomResp.ErrMsg)
+	}
+	return nil
+}
+
+// splitLines 按行将内容拆分为多段，每段不超过 maxBytes 字节，单行超长时截断
+func splitLines(content string, maxBytes int) []string {
+	var parts []string
+	var current string
+	for _, line := range strings.Split(content, "\n") {
+		line = truncateBytes(line, maxBytes)
+		if current != "" && len(current)+1+len(line) > maxBytes {
+			parts = append(parts, current)
+			current = ""
+		}
+		if current == "" {
+			current = line
+		} else {
+			current += "\n" + line
+		}
+	}
+	return append(parts, current)
+}
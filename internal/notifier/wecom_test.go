@@ -0,0 +1,122 @@
+package notifier
+
+import (
+	"easy-check/internal/db"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWeComNotifierSplitsLongMessages(t *testing.T) {
+	var received []WeComMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message WeComMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Error(err)
+		}
+		received = append(received, message)
+		json.NewEncoder(w).Encode(WeComResponse{ErrMsg: "ok"})
+	}))
+	defer server.Close()
+
+	log := newTestLogger(t)
+	n, err := NewWeComNotifier(map[string]interface{}{
+		"webhook":        server.URL,
+		"msg_type":       "markdown",
+		"mentioned_list": []interface{}{"zhangsan"},
+	}, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var alerts []*db.AlertStatus
+	for i := 0; i < 100; i++ {
+		alerts = append(alerts, &db.AlertStatus{
+			Host:        fmt.Sprintf("10.0.0.%d", i),
+			Description: "核心交换机下联服务器",
+			Status:      db.StatusAlert,
+			Detail:      "packet loss 100%",
+		})
+	}
+	if err := n.SendAggregatedNotification(alerts, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(received) < 2 {
+		t.Fatalf("expected the message to be split, got %d message(s)", len(received))
+	}
+	lines := 0
+	for i, message := range received {
+		content := message.Markdown.Content
+		if len(content) > wecomMarkdownMaxBytes {
+			t.Errorf("message %d has %d bytes", i, len(content))
+		}
+		if !strings.Contains(content, fmt.Sprintf("（%d/%d）", i+1, len(received))) {
+			t.Errorf("message %d has no part number: %q", i, content[:80])
+		}
+		if mentioned := strings.Contains(content, "<@zhangsan>"); mentioned != (i == 0) {
+			t.Errorf("message %d mentioned=%v", i, mentioned)
+		}
+		lines += strings.Count(content, "- 开始时间")
+	}
+	if lines != len(alerts) {
+		t.Fatalf("expected %d hosts across messages, got %d", len(alerts), lines)
+	}
+}
+
+func TestWeComNotifierAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(WeComResponse{ErrCode: 93000, ErrMsg: "invalid webhook url"})
+	}))
+	defer server.Close()
+
+	log := newTestLogger(t)
+	n, err := NewWeComNotifier(map[string]interface{}{"webhook": server.URL}, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = n.SendReport("report", "content")
+	if err == nil || !strings.Contains(err.Error(), "code=93000") {
+		t.Fatalf("expected API error, got %v", err)
+	}
+}
+
+func TestWeComNotifierPartialSendSucceeds(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 2 {
+			json.NewEncoder(w).Encode(WeComResponse{ErrCode: 45009, ErrMsg: "api freq out of limit"})
+			return
+		}
+		json.NewEncoder(w).Encode(WeComResponse{ErrMsg: "ok"})
+	}))
+	defer server.Close()
+
+	log := newTestLogger(t)
+	n, err := NewWeComNotifier(map[string]interface{}{"webhook": server.URL}, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := strings.Repeat(strings.Repeat("x", 100)+"\n", 50)
+	if err := n.SendReport("report", content); err != nil {
+		t.Fatalf("partial send should not be retried, got %v", err)
+	}
+	if requests < 3 {
+		t.Fatalf("expected every part to be attempted, got %d request(s)", requests)
+	}
+}
+
+func TestWeComNotifierRejectsOversizedTitle(t *testing.T) {
+	log := newTestLogger(t)
+	n, err := NewWeComNotifier(map[string]interface{}{"webhook": "http://127.0.0.1:0"}, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.SendReport(strings.Repeat("标题", 1000), "content"); err == nil {
+		t.Fatal("expected an error when the title leaves no room for content")
+	}
+}
@@ -0,0 +1,116 @@
+package report
+
+import (
+	"easy-check/internal/config"
+	"easy-check/internal/db"
+	"easy-check/internal/logger"
+	"easy-check/internal/types"
+	"easy-check/internal/utils"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultReportSchedule 未配置发送时间时每周一 09:00 发送
+const defaultReportSchedule = "0 9 * * 1"
+
+// Reporter 按 sla.report 配置定期发送可用性报告
+type Reporter struct {
+	alerts   *db.AlertStatusManager
+	tsdb     *db.TSDB
+	notifier types.Notifier
+	logger   *logger.Logger
+
+	mu       sync.RWMutex
+	cfg      *config.Config
+	schedule *utils.CronSchedule
+	stop     chan struct{}
+	lastRun  time.Time
+}
+
+// NewReporter 创建可用性报告发送器
+func NewReporter(cfg *config.Config, alerts *db.AlertStatusManager, tsdb *db.TSDB, notifier types.Notifier, logger *logger.Logger) *Reporter {
+	r := &Reporter{
+		alerts:   alerts,
+		tsdb:     tsdb,
+		notifier: notifier,
+		logger:   logger,
+		stop:     make(chan struct{}),
+	}
+	r.UpdateConfig(cfg)
+	return r
+}
+
+// UpdateConfig 更新配置并重新解析发送时间
+func (r *Reporter) UpdateConfig(cfg *config.Config) {
+	var schedule *utils.CronSchedule
+	if cfg.SLA.Report.Enable {
+		expr := cfg.SLA.Report.Schedule
+		if expr == "" {
+			expr = defaultReportSchedule
+		}
+		var err error
+		if schedule, err = utils.ParseCron(expr); err != nil {
+			r.logger.Log(fmt.Sprintf("Invalid sla report schedule %q, report disabled: %v", expr, err), "error")
+		}
+	}
+
+	r.mu.Lock()
+	r.cfg = cfg
+	r.schedule = schedule
+	r.mu.Unlock()
+}
+
+// Start 每分钟检查一次是否到达发送时间，阻塞直到 Stop
+func (r *Reporter) Start() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			r.tick(now)
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// Stop 停止定期发送
+func (r *Reporter) Stop() {
+	close(r.stop)
+}
+
+func (r *Reporter) tick(now time.Time) {
+	minute := now.Truncate(time.Minute)
+
+	r.mu.Lock()
+	cfg, schedule := r.cfg, r.schedule
+	due := schedule != nil && schedule.Match(minute) && !minute.Equal(r.lastRun)
+	if due {
+		r.lastRun = minute
+	}
+	r.mu.Unlock()
+
+	if due {
+		if err := r.Send(cfg, now); err != nil {
+			r.logger.Log(fmt.Sprintf("Failed to send sla report: %v", err), "error")
+		}
+	}
+}
+
+// Send 统计 now 之前最近一个完整周期的可用性并发送报告
+func (r *Reporter) Send(cfg *config.Config, now time.Time) error {
+	start, end := ReportRange(cfg.SLA.Report.Period, now)
+	report, err := BuildSLAReport(cfg, r.alerts, r.tsdb, start, end)
+	if err != nil {
+		return err
+	}
+
+	title, content := FormatSLAReport(report)
+	if err := r.notifier.SendReport(title, content); err != nil {
+		return err
+	}
+	r.logger.Log(fmt.Sprintf("Sent sla report for %s ~ %s, %d breaches", report.Start, report.End, report.Breaches), "info")
+	return nil
+}
@@ -0,0 +1,287 @@
+package report
+
+import (
+	"easy-check/internal/config"
+	"easy-check/internal/db"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 报告统计周期
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// HostAvailability 单个主机在统计范围内的可用性
+type HostAvailability struct {
+	Host            string  `json:"host"`
+	Description     string  `json:"description"`
+	Uptime          float64 `json:"uptime"`           // 可用率（百分比）
+	Downtime        int64   `json:"downtime"`         // 告警（不可达）总时长（秒）
+	Incidents       int     `json:"incidents"`        // 告警次数
+	Checks          int     `json:"checks"`           // TSDB 中记录的检测次数
+	FailedChecks    int     `json:"failed_checks"`    // TSDB 中记录的失败检测次数
+	Coverage        float64 `json:"coverage"`         // 检测数据覆盖率（百分比），过低时可用率仅供参考
+	Target          float64 `json:"target"`           // 可用性目标（百分比），0 表示未设置
+	ErrorBudget     int64   `json:"error_budget"`     // 目标允许的不可用时长（秒）
+	BudgetRemaining int64   `json:"budget_remaining"` // 剩余错误预算（秒），为负表示已超出
+	Breached        bool    `json:"breached"`         // 是否未达到目标
+}
+
+// GroupAvailability 按标签分组的可用性
+type GroupAvailability struct {
+	Tag      string  `json:"tag"`
+	Hosts    int     `json:"hosts"`
+	Uptime   float64 `json:"uptime"` // 组内主机可用率的平均值
+	Target   float64 `json:"target"`
+	Breaches int     `json:"breaches"` // 组内未达标的主机数
+	Breached bool    `json:"breached"`
+}
+
+// SLAReport 可用性报告
+type SLAReport struct {
+	Start    string              `json:"start"`
+	End      string              `json:"end"`
+	Hosts    []HostAvailability  `json:"hosts"`
+	Groups   []GroupAvailability `json:"groups"`
+	Breaches int                 `json:"breaches"` // 未达标的主机数
+}
+
+// BuildSLAReport 根据故障记录和 TSDB 检测数据计算 [start, end] 内各主机的可用性
+// 可用率取 TSDB 中检测成功的比例，范围内没有检测结果时按故障记录计算；
+// 停机时间取达到 ALERT 的故障持续时间，降级不计入；end 晚于当前时间时只统计到当前
+func BuildSLAReport(cfg *config.Config, alerts *db.AlertStatusManager, tsdb *db.TSDB, start, end time.Time) (*SLAReport, error) {
+	if now := time.Now(); end.After(now) {
+		end = now
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("invalid report range %s ~ %s", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+
+	incidents, err := alerts.GetIncidents("", start, end)
+	if err != nil {
+		return nil, err
+	}
+	byHost := make(map[string][]db.Incident)
+	for _, incident := range incidents {
+		byHost[incident.Host] = append(byHost[incident.Host], incident)
+	}
+
+	hosts := make([]string, 0, len(cfg.Hosts))
+	for _, host := range cfg.Hosts {
+		hosts = append(hosts, host.Key())
+	}
+	checks, results, failed := map[string]int{}, map[string]int{}, map[string]int{}
+	if tsdb != nil {
+		if checks, err = tsdb.QueryOverTime("count_over_time", hosts, "packet_loss", start, end); err != nil {
+			return nil, err
+		}
+		// 早期数据没有 check_failed，只有记录了检测结果的样本参与计算
+		if results, err = tsdb.QueryOverTime("count_over_time", hosts, "check_failed", start, end); err != nil {
+			return nil, err
+		}
+		if failed, err = tsdb.QueryOverTime("sum_over_time", hosts, "check_failed", start, end); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &SLAReport{
+		Start: start.Format(time.RFC3339),
+		End:   end.Format(time.RFC3339),
+	}
+	for _, host := range cfg.Hosts {
+		key := host.Key()
+		availability := computeAvailability(byHost[key], results[key], failed[key], cfg.SLATarget(host), start, end)
+		availability.Host = key
+		availability.Description = host.Description
+		availability.Checks = checks[key]
+		if interval := cfg.EffectivePingConfig(host).Interval; interval > 0 {
+			coverage := float64(availability.Checks*interval) / end.Sub(start).Seconds() * 100
+			availability.Coverage = min(coverage, 100)
+		}
+		if availability.Breached {
+			report.Breaches++
+		}
+		report.Hosts = append(report.Hosts, availability)
+	}
+	report.Groups = groupAvailability(cfg, report.Hosts)
+	return report, nil
+}
+
+// computeAvailability 计算 [start, end] 内的停机时间、可用率和错误预算
+// results 为记录了检测结果的样本数，failed 为其中失败的次数；results 为 0 时可用率按故障记录的停机时间计算
+func computeAvailability(incidents []db.Incident, results, failed int, target float64, start, end time.Time) HostAvailability {
+	total := end.Sub(start)
+	a := HostAvailability{Target: target, Uptime: 100}
+
+	var down time.Duration
+	for _, incident := range incidents {
+		if incident.Status != db.StatusAlert {
+			continue
+		}
+		failTime, err := time.Parse(time.RFC3339, incident.FailTime)
+		if err != nil {
+			continue
+		}
+		recoveryTime := end // 仍未恢复的故障统计到范围结束
+		if incident.RecoveryTime != "" {
+			if recoveryTime, err = time.Parse(time.RFC3339, incident.RecoveryTime); err != nil {
+				continue
+			}
+		}
+		if failTime.Before(start) {
+			failTime = start
+		}
+		if recoveryTime.After(end) {
+			recoveryTime = end
+		}
+		if recoveryTime.After(failTime) {
+			down += recoveryTime.Sub(failTime)
+			a.Incidents++
+		}
+	}
+
+	a.Downtime = int64(down.Seconds())
+	a.FailedChecks = failed
+	switch {
+	case results > 0:
+		a.Uptime = (1 - float64(failed)/float64(results)) * 100
+	case total > 0:
+		a.Uptime = (1 - down.Seconds()/total.Seconds()) * 100
+	}
+	if target > 0 {
+		// 错误预算按可用率折算的不可用时长扣减
+		a.ErrorBudget = int64(total.Seconds() * (100 - target) / 100)
+		a.BudgetRemaining = a.ErrorBudget - int64(math.Round(total.Seconds()*(100-a.Uptime)/100))
+		a.Breached = a.Uptime < target
+	}
+	return a
+}
+
+// groupAvailability 按主机标签汇总可用性，结果按标签排序
+func groupAvailability(cfg *config.Config, hosts []HostAvailability) []GroupAvailability {
+	byHost := make(map[string]HostAvailability, len(hosts))
+	for _, h := range hosts {
+		byHost[h.Host] = h
+	}
+
+	groups := make(map[string]*GroupAvailability)
+	for _, host := range cfg.Hosts {
+		h, ok := byHost[host.Key()]
+		if !ok {
+			continue
+		}
+		for _, tag := range host.Tags {
+			group, ok := groups[tag]
+			if !ok {
+				group = &GroupAvailability{Tag: tag, Target: cfg.GroupSLATarget(tag)}
+				groups[tag] = group
+			}
+			group.Hosts++
+			group.Uptime += h.Uptime
+			if h.Breached {
+				group.Breaches++
+			}
+		}
+	}
+
+	result := make([]GroupAvailability, 0, len(groups))
+	for _, group := range groups {
+		group.Uptime /= float64(group.Hosts)
+		group.Breached = group.Target > 0 && group.Uptime < group.Target
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Tag < result[j].Tag })
+	return result
+}
+
+// ReportRange 返回 now 之前最近一个完整统计周期的起止时间，周从周一开始
+func ReportRange(period string, now time.Time) (start, end time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case PeriodDay:
+		return today.AddDate(0, 0, -1), today
+	case PeriodMonth:
+		end = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		return end.AddDate(0, -1, 0), end
+	default:
+		offset := (int(today.Weekday()) + 6) % 7 // 距离本周一的天数
+		end = today.AddDate(0, 0, -offset)
+		return end.AddDate(0, 0, -7), end
+	}
+}
+
+// FormatSLAReport 将报告格式化为通知的标题和内容
+func FormatSLAReport(r *SLAReport) (title, content string) {
+	title = "📊【easy-check】：可用性报告"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "统计周期：%s ~ %s\n", formatReportTime(r.Start), formatReportTime(r.End))
+	fmt.Fprintf(&b, "主机数：%d，未达标：%d\n", len(r.Hosts), r.Breaches)
+
+	hosts := append([]HostAvailability(nil), r.Hosts...)
+	sort.SliceStable(hosts, func(i, j int) bool { return hosts[i].Uptime < hosts[j].Uptime })
+	for _, h := range hosts {
+		mark := "✅"
+		if h.Breached {
+			mark = "❌"
+		}
+		fmt.Fprintf(&b, "%s [%s] %s 可用率 %.3f%%", mark, h.Description, h.Host, h.Uptime)
+		if h.Target > 0 {
+			fmt.Fprintf(&b, "（目标 %g%%）", h.Target)
+		}
+		if h.Incidents > 0 {
+			fmt.Fprintf(&b, " | 告警 %d 次，停机 %s", h.Incidents, formatSeconds(h.Downtime))
+		}
+		if h.Target > 0 {
+			if h.BudgetRemaining >= 0 {
+				fmt.Fprintf(&b, " | 剩余预算 %s", formatSeconds(h.BudgetRemaining))
+			} else {
+				fmt.Fprintf(&b, " | 超出预算 %s", formatSeconds(-h.BudgetRemaining))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	for _, g := range r.Groups {
+		fmt.Fprintf(&b, "标签 %s：%d 台主机，平均可用率 %.3f%%", g.Tag, g.Hosts, g.Uptime)
+		if g.Target > 0 {
+			fmt.Fprintf(&b, "（目标 %g%%），未达标 %d 台", g.Target, g.Breaches)
+		}
+		b.WriteString("\n")
+	}
+	return title, strings.TrimRight(b.String(), "\n")
+}
+
+func formatReportTime(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// formatSeconds 将秒数格式化为“1天2小时3分钟”的形式
+func formatSeconds(seconds int64) string {
+	d := time.Duration(seconds) * time.Second
+	days := int64(d / (24 * time.Hour))
+	hours := int64(d % (24 * time.Hour) / time.Hour)
+	minutes := int64(d % time.Hour / time.Minute)
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%d天", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%d小时", hours))
+	}
+	if minutes > 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%d分钟", minutes))
+	}
+	return strings.Join(parts, "")
+}
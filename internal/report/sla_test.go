@@ -0,0 +1,65 @@
+package report
+
+import (
+	"easy-check/internal/db"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestComputeAvailability(t *testing.T) {
+	start := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+	incidents := []db.Incident{
+		// 从范围开始前持续到 00:30，只计入 30 分钟
+		{Status: db.StatusAlert, FailTime: start.Add(-time.Hour).Format(time.RFC3339), RecoveryTime: start.Add(30 * time.Minute).Format(time.RFC3339)},
+		// 降级不计入停机时间
+		{Status: db.StatusDegraded, FailTime: start.Add(2 * time.Hour).Format(time.RFC3339), RecoveryTime: start.Add(3 * time.Hour).Format(time.RFC3339)},
+		// 仍未恢复，统计到范围结束
+		{Status: db.StatusAlert, FailTime: end.Add(-30 * time.Minute).Format(time.RFC3339)},
+	}
+
+	a := computeAvailability(incidents, 0, 0, 99.9, start, end)
+	if a.Incidents != 2 || a.Downtime != 3600 {
+		t.Fatalf("expected 2 incidents and 3600s downtime, got %d and %d", a.Incidents, a.Downtime)
+	}
+	if want := (1 - 3600.0/86400) * 100; math.Abs(a.Uptime-want) > 1e-9 {
+		t.Fatalf("expected uptime %f, got %f", want, a.Uptime)
+	}
+	if a.ErrorBudget != 86 || a.BudgetRemaining != 86-3600 || !a.Breached {
+		t.Fatalf("unexpected error budget: %+v", a)
+	}
+
+	a = computeAvailability(nil, 0, 0, 0, start, end)
+	if a.Uptime != 100 || a.Breached || a.ErrorBudget != 0 {
+		t.Fatalf("expected full uptime without target, got %+v", a)
+	}
+
+	// 有检测结果时按失败检测的比例计算，故障记录只用于统计告警次数和停机时长
+	a = computeAvailability(incidents, 1000, 2, 99.9, start, end)
+	if math.Abs(a.Uptime-99.8) > 1e-9 || a.FailedChecks != 2 || a.Downtime != 3600 || !a.Breached {
+		t.Fatalf("expected check-based uptime 99.8, got %+v", a)
+	}
+	if a.BudgetRemaining != 86-173 { // 0.2% 的一天约 173 秒
+		t.Fatalf("expected budget remaining %d, got %d", 86-173, a.BudgetRemaining)
+	}
+}
+
+func TestReportRange(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC) // 周三
+
+	tests := []struct {
+		period     string
+		start, end time.Time
+	}{
+		{PeriodDay, time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)},
+		{PeriodWeek, time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)},
+		{PeriodMonth, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		start, end := ReportRange(tt.period, now)
+		if !start.Equal(tt.start) || !end.Equal(tt.end) {
+			t.Errorf("%s: expected %v ~ %v, got %v ~ %v", tt.period, tt.start, tt.end, start, end)
+		}
+	}
+}
@@ -5,44 +5,206 @@ import (
 	"easy-check/internal/config"
 	"easy-check/internal/logger"
 	"fmt"
+	"math/rand/v2"
+	"sync"
 	"time"
 )
 
-func StartPeriodicPingChecks(chk *checker.Checker, cfg *config.Config, logger *logger.Logger, tickerControlChan chan time.Duration) chan struct{} {
-	// 优先使用 ping.interval，如果未配置则使用全局 interval
-	interval := cfg.Interval
-	if cfg.Ping.Interval > 0 {
-		interval = cfg.Ping.Interval
-		logger.Log(fmt.Sprintf("Using ping-specific interval: %d seconds", interval), "debug")
-	} else {
-		logger.Log(fmt.Sprintf("Using global interval: %d seconds", interval), "debug")
+// Scheduler 按检测维护下次检测时间，每个检测按各自生效的 interval 执行
+// 同一地址上配置的多个检测（如 ping 和 tcp:443）按 config.Host.Key 区分，各自独立调度
+// 首次检测时间在一个间隔内随机分散，避免大量主机在同一秒集中检测
+type Scheduler struct {
+	checker *checker.Checker
+	logger  *logger.Logger
+
+	mu    sync.Mutex
+	hosts map[string]*scheduledHost // key 为检测标识 config.Host.Key
+
+	wakeChan chan struct{} // 主机列表变化后唤醒调度循环
+	stopChan chan struct{}
+}
+
+// scheduledHost 单个主机的调度状态
+type scheduledHost struct {
+	host     config.Host
+	interval time.Duration
+	nextRun  time.Time
+	running  bool // 上一次检测尚未结束
+}
+
+func NewScheduler(chk *checker.Checker, logger *logger.Logger) *Scheduler {
+	return &Scheduler{
+		checker:  chk,
+		logger:   logger,
+		hosts:    make(map[string]*scheduledHost),
+		wakeChan: make(chan struct{}, 1),
+		stopChan: make(chan struct{}),
 	}
+}
 
-	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+// Start 加载主机列表并启动调度循环
+func (s *Scheduler) Start(cfg *config.Config) {
+	s.UpdateConfig(cfg)
+	s.logger.Log("Starting periodic ping checks", "info")
+	go s.run()
+}
 
-	logger.Log("Starting periodic ping checks", "info")
+// Stop 停止调度循环，正在进行的检测不会被中断
+func (s *Scheduler) Stop() {
+	close(s.stopChan)
+}
 
-	// 创建一个停止通道，用于通知程序退出
-	stopChan := make(chan struct{})
+// UpdateConfig 同步主机列表：新增主机加入调度，删除的主机移出调度，
+// 间隔变化的主机重新计算下次检测时间，无需重启调度循环
+func (s *Scheduler) UpdateConfig(cfg *config.Config) {
+	s.mu.Lock()
+	s.syncHosts(cfg, time.Now())
+	s.mu.Unlock()
 
-	// 处理 ping 检查的 goroutine
-	go func() {
-		defer ticker.Stop() // 移到 goroutine 内部，这样只有在 goroutine 结束时才会停止 ticker
-		for {
-			select {
-			case <-ticker.C:
-				logger.Log("Executing scheduled ping check", "debug")
-				chk.PingHosts()
-			case newInterval := <-tickerControlChan:
-				// 收到新的间隔时间，更新定时器
-				ticker.Stop()
-				ticker = time.NewTicker(newInterval)
-				logger.Log(fmt.Sprintf("Updated ping check interval to %v", newInterval), "info")
-			case <-stopChan:
-				return
+	// 非阻塞唤醒，调度循环会重新计算等待时间
+	select {
+	case s.wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+// syncHosts 根据配置更新调度表，调用方需持有 mu
+func (s *Scheduler) syncHosts(cfg *config.Config, now time.Time) {
+	seen := make(map[string]bool, len(cfg.Hosts))
+	for _, host := range cfg.Hosts {
+		key := host.Key()
+		if seen[key] {
+			s.logger.Log(fmt.Sprintf("Duplicate check %s in config, only the last one is scheduled", key), "warn")
+		}
+		seen[key] = true
+		interval := hostInterval(cfg, host)
+
+		sh, ok := s.hosts[key]
+		if !ok {
+			s.hosts[key] = &scheduledHost{
+				host:     host,
+				interval: interval,
+				nextRun:  now.Add(jitter(interval)),
+			}
+			s.logger.Log(fmt.Sprintf("Scheduled check %s every %v", key, interval), "debug")
+			continue
+		}
+
+		sh.host = host
+		if sh.interval != interval {
+			sh.interval = interval
+			sh.nextRun = now.Add(jitter(interval))
+			s.logger.Log(fmt.Sprintf("Interval of check %s changed to %v", key, interval), "info")
+		}
+	}
+
+	for key := range s.hosts {
+		if !seen[key] {
+			delete(s.hosts, key)
+			s.logger.Log(fmt.Sprintf("Unscheduled removed check %s", key), "debug")
+		}
+	}
+}
+
+// run 调度循环，每次等待到最早的下次检测时间
+func (s *Scheduler) run() {
+	timer := time.NewTimer(s.nextWait(time.Now()))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-s.wakeChan:
+		case <-timer.C:
+			s.runDueHosts(time.Now())
+		}
+		timer.Reset(s.nextWait(time.Now()))
+	}
+}
+
+// runDueHosts 将所有到期的主机交给 worker 池检测，
+// 上一次检测未结束（含仍在排队）或队列已满的主机跳过本轮
+func (s *Scheduler) runDueHosts(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	submitted := 0
+	for key, sh := range s.hosts {
+		if sh.nextRun.After(now) {
+			continue
+		}
+
+		// 按固定频率推进，保持首次检测时的分散；落后太多时从当前时间重新计算
+		sh.nextRun = sh.nextRun.Add(sh.interval)
+		if !sh.nextRun.After(now) {
+			sh.nextRun = now.Add(sh.interval)
+		}
+
+		if sh.running {
+			s.checker.RecordSkipped(1, fmt.Sprintf("previous check %s still running", key))
+			continue
+		}
+
+		wg.Add(1)
+		ok := s.checker.SubmitCheck(sh.host, func() {
+			s.mu.Lock()
+			if sh, ok := s.hosts[key]; ok {
+				sh.running = false
 			}
+			s.mu.Unlock()
+			wg.Done()
+		})
+		if !ok {
+			wg.Done()
+			s.checker.RecordSkipped(1, fmt.Sprintf("queue full, check %s", key))
+			continue
 		}
+		sh.running = true
+		submitted++
+	}
+
+	if submitted == 0 {
+		return
+	}
+	// 本轮到期的检测全部结束后记录耗时
+	go func() {
+		wg.Wait()
+		s.checker.RecordCycle(time.Since(now), submitted)
 	}()
+}
+
+// nextWait 返回距离最早的下次检测时间的等待时长
+func (s *Scheduler) nextWait(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	return stopChan
+	wait := time.Minute // 没有主机时定期醒来，主机变化时会被提前唤醒
+	for _, sh := range s.hosts {
+		if d := sh.nextRun.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+// hostInterval 获取主机生效的检测间隔
+func hostInterval(cfg *config.Config, host config.Host) time.Duration {
+	interval := cfg.EffectivePingConfig(host).Interval
+	if interval <= 0 {
+		interval = 10 // 未配置任何间隔时的默认值
+	}
+	return time.Duration(interval) * time.Second
+}
+
+// jitter 返回 [0, interval) 内的随机偏移
+func jitter(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 0
+	}
+	return rand.N(interval)
 }
@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"easy-check/internal/config"
+	"easy-check/internal/logger"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestLogger 创建只输出错误日志、文件写入临时目录的测试日志
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	return logger.NewLogger(logger.Config{
+		File:         filepath.Join(t.TempDir(), "test.log"),
+		ConsoleLevel: "error",
+		FileLevel:    "error",
+	})
+}
+
+func TestSyncHosts(t *testing.T) {
+	log := newTestLogger(t)
+	s := NewScheduler(nil, log)
+	now := time.Now()
+
+	cfg := &config.Config{
+		Interval: 10,
+		Hosts: []config.Host{
+			{Host: "a"},
+			{Host: "b", Interval: 60},
+		},
+	}
+	s.syncHosts(cfg, now)
+
+	if len(s.hosts) != 2 {
+		t.Fatalf("got %d scheduled hosts, want 2", len(s.hosts))
+	}
+	for key, want := range map[string]time.Duration{"a": 10 * time.Second, "b": time.Minute} {
+		sh := s.hosts[key]
+		if sh.interval != want {
+			t.Errorf("host %s interval = %v, want %v", key, sh.interval, want)
+		}
+		if sh.nextRun.Before(now) || !sh.nextRun.Before(now.Add(want)) {
+			t.Errorf("host %s first run %v not within one interval", key, sh.nextRun.Sub(now))
+		}
+	}
+
+	// 未变化的主机保留原有的下次检测时间
+	aNext := s.hosts["a"].nextRun
+
+	// 删除 b、新增 c
+	cfg = &config.Config{
+		Interval: 10,
+		Hosts: []config.Host{
+			{Host: "a"},
+			{Host: "c", Interval: 30},
+		},
+	}
+	s.syncHosts(cfg, now.Add(time.Second))
+
+	if _, ok := s.hosts["b"]; ok {
+		t.Error("removed host b is still scheduled")
+	}
+	if _, ok := s.hosts["c"]; !ok {
+		t.Error("added host c is not scheduled")
+	}
+	if !s.hosts["a"].nextRun.Equal(aNext) {
+		t.Error("unchanged host a was rescheduled")
+	}
+
+	cfg.Ping.Interval = 20
+	s.syncHosts(cfg, now.Add(2*time.Second))
+	if got := s.hosts["a"].interval; got != 20*time.Second {
+		t.Errorf("host a interval after reload = %v, want 20s", got)
+	}
+}
+
+func TestSyncHostsSameAddress(t *testing.T) {
+	log := newTestLogger(t)
+	s := NewScheduler(nil, log)
+
+	// 同一地址上的多个检测各自调度
+	cfg := &config.Config{
+		Interval: 10,
+		Hosts: []config.Host{
+			{Host: "example.com"},
+			{Host: "example.com", Type: config.HostTypeTCP, Port: 443},
+			{Host: "example.com", Type: config.HostTypeTLS},
+			{Host: "example.com", Type: config.HostTypeHTTP, URL: "https://example.com/health"},
+		},
+	}
+	s.syncHosts(cfg, time.Now())
+
+	for _, key := range []string{"example.com", "tcp://example.com:443", "tls://example.com:443", "https://example.com/health"} {
+		sh, ok := s.hosts[key]
+		if !ok {
+			t.Errorf("check %s is not scheduled", key)
+			continue
+		}
+		if sh.host.Key() != key {
+			t.Errorf("check %s scheduled with host of %s", key, sh.host.Key())
+		}
+	}
+	if len(s.hosts) != len(cfg.Hosts) {
+		t.Errorf("got %d scheduled checks, want %d", len(s.hosts), len(cfg.Hosts))
+	}
+}
@@ -2,10 +2,13 @@ package services
 
 import (
 	"context"
+	"easy-check/internal/checker"
 	"easy-check/internal/config"
 	"easy-check/internal/constants"
 	"easy-check/internal/data"
+	"easy-check/internal/db"
 	"easy-check/internal/initializer"
+	"easy-check/internal/report"
 	"easy-check/internal/types"
 	"easy-check/internal/update"
 	"easy-check/internal/utils"
@@ -225,7 +228,7 @@ func (a *AppService) GetHosts(page int, pageSize int, searchTerm string) *types.
 	var result []types.Host
 	for _, h := range hosts {
 		result = append(result, types.Host{
-			Host:        h.Host,
+			Host:        h.Key(),
 			Description: h.Description,
 		})
 	}
@@ -237,9 +240,152 @@ func (a *AppService) GetHosts(page int, pageSize int, searchTerm string) *types.
 	}
 }
 
+// GetHostPingConfig 获取主机实际生效的 ping 配置（主机配置 > 全局 ping 配置 > 默认值）
+func (a *AppService) GetHostPingConfig(host string) (*types.HostPingConfig, error) {
+	if a.appCtx == nil || a.appCtx.Config == nil {
+		return nil, fmt.Errorf("配置未初始化")
+	}
+
+	cfg := a.appCtx.Config
+	for _, h := range cfg.Hosts {
+		if h.Key() != host {
+			continue
+		}
+		p := cfg.EffectivePingConfig(h)
+		return &types.HostPingConfig{
+			Host:           h.Key(),
+			Count:          p.Count,
+			Timeout:        p.Timeout,
+			Interval:       p.Interval,
+			LossRate:       p.LossRate,
+			PacketInterval: p.PacketInterval,
+			IPVersion:      p.IPVersion,
+		}, nil
+	}
+	return nil, fmt.Errorf("主机不存在: %s", host)
+}
+
+// GetCheckerStats 获取检测调度的运行指标（最近一轮耗时、跳过次数、队列长度）
+func (a *AppService) GetCheckerStats() (*types.CheckerStats, error) {
+	stats := &types.CheckerStats{}
+	fields := map[string]*float64{
+		"cycle_duration":       &stats.CycleDuration,
+		"cycle_checks":         &stats.CycleChecks,
+		"skipped_checks_total": &stats.SkippedChecksTotal,
+		"queue_length":         &stats.QueueLength,
+	}
+	for metric, field := range fields {
+		values, err := data.GetHostMetrics(a.appCtx.TSDB, []string{checker.SelfMetricsHost}, metric)
+		if err != nil {
+			return nil, fmt.Errorf("查询指标 %s 失败: %v", metric, err)
+		}
+		*field = values[checker.SelfMetricsHost]
+	}
+	return stats, nil
+}
+
+// AcknowledgeAlert 确认主机当前的告警，确认后不再重复通知和升级，恢复通知中会注明确认人
+func (a *AppService) AcknowledgeAlert(host, by, comment string) (*db.AlertStatus, error) {
+	if a.appCtx == nil || a.appCtx.AlertStatusMgr == nil {
+		return nil, fmt.Errorf("告警状态管理未初始化")
+	}
+	if by == "" {
+		return nil, fmt.Errorf("确认人不能为空")
+	}
+	status, err := a.appCtx.AlertStatusMgr.Acknowledge(host, by, comment)
+	if err != nil {
+		return nil, fmt.Errorf("确认告警失败: %v", err)
+	}
+	return &status, nil
+}
+
+// UnacknowledgeAlert 取消确认，恢复重复通知和升级
+func (a *AppService) UnacknowledgeAlert(host string) (*db.AlertStatus, error) {
+	if a.appCtx == nil || a.appCtx.AlertStatusMgr == nil {
+		return nil, fmt.Errorf("告警状态管理未初始化")
+	}
+	status, err := a.appCtx.AlertStatusMgr.Unacknowledge(host)
+	if err != nil {
+		return nil, fmt.Errorf("取消确认失败: %v", err)
+	}
+	return &status, nil
+}
+
+// GetIncidents 查询故障历史，host 为空时查询所有主机，startTime/endTime 为毫秒时间戳，0 表示不限制
+func (a *AppService) GetIncidents(host string, startTime, endTime int64) ([]db.Incident, error) {
+	if a.appCtx == nil || a.appCtx.AlertStatusMgr == nil {
+		return nil, fmt.Errorf("告警状态管理未初始化")
+	}
+	start, end := millisRange(startTime, endTime)
+	return a.appCtx.AlertStatusMgr.GetIncidents(host, start, end)
+}
+
+// GetIncidentStats 按主机统计故障次数、MTTR 和 MTBF，参数同 GetIncidents
+func (a *AppService) GetIncidentStats(host string, startTime, endTime int64) ([]db.IncidentStats, error) {
+	if a.appCtx == nil || a.appCtx.AlertStatusMgr == nil {
+		return nil, fmt.Errorf("告警状态管理未初始化")
+	}
+	start, end := millisRange(startTime, endTime)
+	return a.appCtx.AlertStatusMgr.GetIncidentStats(host, start, end)
+}
+
+// GetSLAReport 计算 [startTime, endTime]（毫秒时间戳）内各主机的可用率、错误预算和未达标情况
+func (a *AppService) GetSLAReport(startTime, endTime int64) (*report.SLAReport, error) {
+	if a.appCtx == nil || a.appCtx.AlertStatusMgr == nil {
+		return nil, fmt.Errorf("告警状态管理未初始化")
+	}
+	start, end := time.UnixMilli(startTime), time.UnixMilli(endTime)
+	return report.BuildSLAReport(a.appCtx.Config, a.appCtx.AlertStatusMgr, a.appCtx.TSDB, start, end)
+}
+
+// millisRange 将毫秒时间戳转换为时间，0 转换为零值表示不限制
+func millisRange(startTime, endTime int64) (start, end time.Time) {
+	if startTime > 0 {
+		start = time.UnixMilli(startTime)
+	}
+	if endTime > 0 {
+		end = time.UnixMilli(endTime)
+	}
+	return start, end
+}
+
+// ListSilences 获取所有静默规则
+func (a *AppService) ListSilences() ([]db.Silence, error) {
+	if a.appCtx == nil || a.appCtx.SilenceMgr == nil {
+		return nil, fmt.Errorf("静默管理未初始化")
+	}
+	return a.appCtx.SilenceMgr.ListSilences()
+}
+
+// SaveSilence 新增或更新静默规则，ID 为空时新增
+func (a *AppService) SaveSilence(silence db.Silence) (*db.Silence, error) {
+	if a.appCtx == nil || a.appCtx.SilenceMgr == nil {
+		return nil, fmt.Errorf("静默管理未初始化")
+	}
+	saved, err := a.appCtx.SilenceMgr.SaveSilence(silence)
+	if err != nil {
+		return nil, fmt.Errorf("保存静默规则失败: %v", err)
+	}
+	return &saved, nil
+}
+
+// DeleteSilence 删除静默规则
+func (a *AppService) DeleteSilence(id string) error {
+	if a.appCtx == nil || a.appCtx.SilenceMgr == nil {
+		return fmt.Errorf("静默管理未初始化")
+	}
+	return a.appCtx.SilenceMgr.DeleteSilence(id)
+}
+
+// hostMetrics 前端展示的主机指标
+var hostMetrics = []string{
+	"min_latency", "avg_latency", "max_latency", "packet_loss",
+	"stddev_latency", "jitter", "p50_latency", "p95_latency", "p99_latency",
+}
+
 // GetStatusWithHosts retrieves latency data and status for hosts
 func (a *AppService) GetStatusWithHosts(hosts []string) *types.HostsStatusResponse {
-	metrics := []string{"min_latency", "avg_latency", "max_latency", "packet_loss"}
+	metrics := hostMetrics
 	latencyData := make([]types.HostStatusData, 0)
 
 	// 获取主机状态
@@ -285,6 +431,16 @@ func (a *AppService) GetStatusWithHosts(hosts []string) *types.HostsStatusRespon
 				existing.MaxLatency = value
 			case "packet_loss":
 				existing.PacketLoss = value
+			case "stddev_latency":
+				existing.StdDevLatency = value
+			case "jitter":
+				existing.Jitter = value
+			case "p50_latency":
+				existing.P50Latency = value
+			case "p95_latency":
+				existing.P95Latency = value
+			case "p99_latency":
+				existing.P99Latency = value
 			}
 		}
 	}
@@ -305,7 +461,7 @@ func (a *AppService) GetStatusWithHosts(hosts []string) *types.HostsStatusRespon
 
 // GetHistoryWithHosts 获取主机历史数据
 func (a *AppService) GetHistoryWithHosts(hosts []string, startTime, endTime int64, step int64) *types.HostsRangeResponse {
-	metrics := []string{"min_latency", "avg_latency", "max_latency", "packet_loss"}
+	metrics := hostMetrics
 
 	if endTime <= startTime {
 		return &types.HostsRangeResponse{Hosts: nil, Total: 0, Error: "endTime must be > startTime"}
@@ -2,10 +2,29 @@ package types
 
 // Host 定义前端需要的主机类型
 type Host struct {
-	Host        string `json:"host"`
+	Host        string `json:"host"` // 检测标识（config.Host.Key），用于查询状态和指标，ping 检测即主机地址
 	Description string `json:"description"`
 }
 
+// HostPingConfig 主机实际生效的 ping 配置
+type HostPingConfig struct {
+	Host           string  `json:"host"`
+	Count          int     `json:"count"`
+	Timeout        int     `json:"timeout"`
+	Interval       int     `json:"interval"`
+	LossRate       float64 `json:"loss_rate"`
+	PacketInterval int     `json:"packet_interval"`
+	IPVersion      string  `json:"ip_version"`
+}
+
+// CheckerStats 检测调度的运行指标，用于评估并发数和队列长度是否足够
+type CheckerStats struct {
+	CycleDuration      float64 `json:"cycle_duration"`       // 最近一轮检测耗时（毫秒）
+	CycleChecks        float64 `json:"cycle_checks"`         // 最近一轮检测的主机数
+	SkippedChecksTotal float64 `json:"skipped_checks_total"` // 因上一次检测未结束或队列已满而跳过的检测总数
+	QueueLength        float64 `json:"queue_length"`         // 等待检测的队列长度
+}
+
 // HostsResponse 定义返回给前端的结构体
 type HostsResponse struct {
 	Hosts []Host `json:"hosts"`
@@ -14,12 +33,17 @@ type HostsResponse struct {
 }
 
 type HostStatusData struct {
-	Host       string  `json:"host"`
-	MinLatency float64 `json:"min_latency"`
-	AvgLatency float64 `json:"avg_latency"`
-	MaxLatency float64 `json:"max_latency"`
-	PacketLoss float64 `json:"packet_loss"`
-	Status     string  `json:"status"`
+	Host          string  `json:"host"`
+	MinLatency    float64 `json:"min_latency"`
+	AvgLatency    float64 `json:"avg_latency"`
+	MaxLatency    float64 `json:"max_latency"`
+	PacketLoss    float64 `json:"packet_loss"`
+	StdDevLatency float64 `json:"stddev_latency"`
+	Jitter        float64 `json:"jitter"`
+	P50Latency    float64 `json:"p50_latency"`
+	P95Latency    float64 `json:"p95_latency"`
+	P99Latency    float64 `json:"p99_latency"`
+	Status        string  `json:"status"`
 	// Sent       bool    `json:"sent"`
 }
 
@@ -12,6 +12,8 @@ type Notifier interface {
 	SendNotification(alert *db.AlertStatus, isRecovery bool) error
 	// 发送聚合/恢复告警
 	SendAggregatedNotification(alerts []*db.AlertStatus, isRecovery bool) error
+	// 发送与告警无关的报告，如定期可用性报告
+	SendReport(title, content string) error
 	// 关闭通知器
 	Close() error
 }
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronSchedule(t *testing.T) {
+	// 2026-01-03 是周六
+	at := func(s string) time.Time {
+		ts, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+
+	tests := []struct {
+		expr string
+		time string
+		want bool
+	}{
+		{"0 2 * * 6", "2026-01-03 02:00", true},
+		{"0 2 * * 6", "2026-01-03 02:01", false},
+		{"0 2 * * 6", "2026-01-04 02:00", false},
+		{"*/15 * * * *", "2026-01-04 10:45", true},
+		{"*/15 * * * *", "2026-01-04 10:46", false},
+		{"30 22 * * 1-5", "2026-01-05 22:30", true},
+		{"0 0 1 * 0", "2026-01-01 00:00", true}, // 日和周满足其一即可
+		{"0 0 1 * 7", "2026-01-04 00:00", true}, // 7 表示周日
+		{"0 3 1,15 6 *", "2026-06-15 03:00", true},
+	}
+	for _, tt := range tests {
+		s, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+		}
+		if got := s.Match(at(tt.time)); got != tt.want {
+			t.Errorf("ParseCron(%q).Match(%s) = %v, want %v", tt.expr, tt.time, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) should fail", expr)
+		}
+	}
+}
@@ -163,9 +163,13 @@ func provideAggregator(cfg *config.Config, log *logger.Logger, notifier types.No
 			log.Log("Using default aggregate recovery line template", "info")
 		}
 
+		// 降级行模板为空时沿用告警行模板
+		degradedLineTemplate := cfg.Alert.AggregateDegradedLineTemplate
+
 		return aggregator.NewAggregator(
 			alertLineTemplate,
 			recoveryLineTemplate,
+			degradedLineTemplate,
 			notifier,
 			log,
 			window,
@@ -208,9 +208,12 @@ func provideAggregator(cfg *config.Config, log *logger.Logger, notifier2 types.N
 			log.Log("Using default aggregate recovery line template", "info")
 		}
 
+		degradedLineTemplate := cfg.Alert.AggregateDegradedLineTemplate
+
 		return aggregator.NewAggregator(
 			alertLineTemplate,
-			recoveryLineTemplate, notifier2, log,
+			recoveryLineTemplate,
+			degradedLineTemplate, notifier2, log,
 			window,
 		), nil
 	} else {
@@ -5,12 +5,13 @@ import (
 	"easy-check/internal/checker"
 	"easy-check/internal/config"
 	"easy-check/internal/constants"
-	"easy-check/internal/db"
 	"easy-check/internal/initializer"
 	"easy-check/internal/logger"
 	"easy-check/internal/machineid"
 	"easy-check/internal/notifier"
+	"easy-check/internal/report"
 	"easy-check/internal/router"
+	"easy-check/internal/scheduler"
 	"easy-check/internal/services"
 	"embed"
 	"fmt"
@@ -62,22 +63,28 @@ func main() {
 	fmt.Println("Application context initialized successfully")
 
 	pinger := checker.NewPinger()
-	alertStatusManager, err := db.NewAlertStatusManager(appCtx.DB.Instance, appCtx.Logger, appCtx.Config.Db)
-	if err != nil {
-		appCtx.Logger.Fatal("Failed to create AlertStatusManager", "error")
-	}
+	// 检测器、通知消费者、报告和界面共用同一个 AlertStatusManager，记录的读-改-写才会互斥
+	alertStatusManager := appCtx.AlertStatusMgr
 	chk := checker.NewChecker(appCtx.Config, pinger, appCtx.Logger, alertStatusManager, appCtx.TSDB)
 
+	// 告警/恢复消费者（定时发送告警和恢复通知）
+	interval := time.Duration(appCtx.Config.Alert.AggregateWindow) * time.Second
+	consumer := notifier.NewConsumer(alertStatusManager, appCtx.SilenceMgr, appCtx.Logger, interval, appCtx.AggregatorHandle)
+	consumer.UpdateConfig(appCtx.Config)
+	// 定期发送可用性报告
+	reporter := report.NewReporter(appCtx.Config, alertStatusManager, appCtx.TSDB, appCtx.Notifier, appCtx.Logger)
+
 	// ========== 1. 启动配置文件热加载监听 ==========
-	tickerControlChan := make(chan time.Duration)
+	sched := scheduler.NewScheduler(chk, appCtx.Logger)
 	go config.WatchConfigFile(filepath.Join("configs", "config.yaml"), appCtx.Logger, func(newConfig *config.Config) {
-		oldInterval := appCtx.Config.Interval
-		oldPingInterval := appCtx.Config.Ping.Interval
 		oldLogConfig := appCtx.Config.Log
 		appCtx.Config = newConfig
 		
 		// 更新Checker的配置（线程安全）
 		chk.UpdateConfig(newConfig)
+		// 更新重复通知与升级策略
+		consumer.UpdateConfig(newConfig)
+		reporter.UpdateConfig(newConfig)
 		
 		appCtx.Logger.Log("Configuration reloaded successfully", "info")
 		// 日志配置热更新
@@ -94,23 +101,8 @@ func main() {
 			appCtx.Logger.UpdateConfig(logConfig)
 			appCtx.Logger.Log("Logger configuration updated", "info")
 		}
-		// interval 热更新
-		var newIntervalToUse int
-		if appCtx.Config.Ping.Interval > 0 {
-			newIntervalToUse = appCtx.Config.Ping.Interval
-		} else {
-			newIntervalToUse = appCtx.Config.Interval
-		}
-		var oldIntervalInUse int
-		if oldPingInterval > 0 {
-			oldIntervalInUse = oldPingInterval
-		} else {
-			oldIntervalInUse = oldInterval
-		}
-		if oldIntervalInUse != newIntervalToUse {
-			appCtx.Logger.Log(fmt.Sprintf("Interval changed from %d to %d seconds", oldIntervalInUse, newIntervalToUse), "info")
-			tickerControlChan <- time.Duration(newIntervalToUse) * time.Second
-		}
+		// 同步主机列表和各主机的检测间隔
+		sched.UpdateConfig(newConfig)
 	})
 
 	// ========== 2. 注册路由、HTTP服务、Wails窗口 ==========
@@ -202,7 +194,7 @@ func main() {
 
 	// ========== 3. 启动后台任务（ping 检查、告警消费者） ==========
 	go func() {
-		runBackgroundTask(appCtx, tickerControlChan, chk)
+		runBackgroundTask(appCtx, consumer, reporter, sched)
 	}()
 
 	err = app.Run()
@@ -211,8 +203,8 @@ func main() {
 	}
 }
 
-// runBackgroundTask 启动后台任务，支持 interval 动态调整
-func runBackgroundTask(appCtx *initializer.AppContext, tickerControlChan chan time.Duration, chk *checker.Checker) {
+// runBackgroundTask 启动后台任务，各主机按自己的 interval 调度检测
+func runBackgroundTask(appCtx *initializer.AppContext, consumer *notifier.Consumer, reporter *report.Reporter, sched *scheduler.Scheduler) {
 	defer func() {
 		if r := recover(); r != nil {
 			message := fmt.Sprintf("Recovered from panic: %v", r)
@@ -220,37 +212,11 @@ func runBackgroundTask(appCtx *initializer.AppContext, tickerControlChan chan ti
 		}
 	}()
 
-	alertStatusManager, err := db.NewAlertStatusManager(appCtx.DB.Instance, appCtx.Logger, appCtx.Config.Db)
-	if err != nil {
-		appCtx.Logger.Fatal("Failed to create AlertStatusManager", "error")
-	}
-
-	// 启动告警/恢复消费者（定时发送告警和恢复通知）
-	interval := time.Duration(appCtx.Config.Alert.AggregateWindow) * time.Second
-	consumer := notifier.NewConsumer(alertStatusManager, appCtx.Logger, interval, appCtx.AggregatorHandle)
+	// 启动告警/恢复消费者
 	go consumer.Start()
+	// 启动可用性报告
+	go reporter.Start()
 
-	// 执行初始 ping 检查
-	appCtx.Logger.Log("Performing initial ping check", "info")
-	chk.PingHosts()
-
-	// 动态 interval 定时器
-	var intervalToUse time.Duration
-	if appCtx.Config.Ping.Interval > 0 {
-		intervalToUse = time.Duration(appCtx.Config.Ping.Interval) * time.Second
-	} else {
-		intervalToUse = time.Duration(appCtx.Config.Interval) * time.Second
-	}
-	ticker := time.NewTicker(intervalToUse)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ticker.C:
-			chk.PingHosts()
-		case newInterval := <-tickerControlChan:
-			appCtx.Logger.Log(fmt.Sprintf("Ping interval updated to %v", newInterval), "info")
-			ticker.Stop()
-			ticker = time.NewTicker(newInterval)
-		}
-	}
+	// 启动按主机调度的定期检查，首次检测时间在各自的间隔内分散
+	sched.Start(appCtx.Config)
 }